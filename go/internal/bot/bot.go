@@ -3,11 +3,18 @@ package bot
 import (
 	"fmt"
 	"log"
+	"net/http"
 
 	"discord-anime-bot/internal/commands"
 	"discord-anime-bot/internal/config"
+	"discord-anime-bot/internal/services/ai"
 	"discord-anime-bot/internal/services/anilist"
-	"discord-anime-bot/internal/services/redis"
+	"discord-anime-bot/internal/services/autonotify"
+	"discord-anime-bot/internal/services/claude"
+	"discord-anime-bot/internal/services/digest"
+	"discord-anime-bot/internal/services/openai"
+	"discord-anime-bot/internal/services/settings"
+	"discord-anime-bot/internal/services/storage"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -16,33 +23,60 @@ import (
 type Bot struct {
 	session             *discordgo.Session
 	config              *config.Config
+	store               storage.Store
+	guilds              *settings.Service
+	anilist             *anilist.Client
 	notificationService *anilist.NotificationService
+	registrar           *commands.Registrar
+	recommender         ai.Recommender
+	digestScheduler     *digest.Scheduler
+	autoNotifier        *autonotify.Watcher
+	callbackServer      *http.Server // receives AniList OAuth redirects, nil when linking is disabled
+	metricsServer       *http.Server // serves expvar metrics on an internal address, nil when disabled
 }
 
 // NewBot creates a new bot instance
 func NewBot(cfg *config.Config) (*Bot, error) {
-	// Initialize Redis connection
-	if err := redis.InitRedis(cfg.RedisURL); err != nil {
-		log.Printf("Failed to connect to Redis: %v", err)
-		log.Println("Bot will continue without Redis (features may not work properly)")
-	}
-
 	session, err := discordgo.New("Bot " + cfg.DiscordToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Discord session: %w", err)
 	}
 
+	// Storage shared by every service; falls back to memory when Redis is unreachable
+	store := storage.Open(cfg)
+	guilds := settings.NewService(store)
+
+	// Shared AniList client used by every handler and service
+	anilistClient := anilist.NewClient(cfg, store)
+
+	// Move watchlists saved in the old expiring format to the entry hashes
+	anilistClient.MigrateWatchlists()
+
 	// Initialize notification service
-	notificationService := anilist.NewNotificationService(session)
+	notificationService := anilist.NewNotificationService(session, anilistClient, store, guilds, cfg)
 
 	bot := &Bot{
 		session:             session,
 		config:              cfg,
+		store:               store,
+		guilds:              guilds,
+		anilist:             anilistClient,
 		notificationService: notificationService,
+		registrar:           commands.NewRegistrar(session, cfg),
+		recommender:         newRecommender(cfg),
+		digestScheduler:     digest.NewScheduler(session, anilistClient, guilds, store),
+		autoNotifier:        autonotify.NewWatcher(anilistClient, notificationService, store),
+	}
+	if anilistClient.LinkingEnabled() {
+		bot.callbackServer = bot.newCallbackServer()
+	}
+	if cfg.MetricsAddr != "" {
+		bot.metricsServer = newMetricsServer(cfg.MetricsAddr)
 	}
 
 	// Add event handlers
 	session.AddHandler(bot.ready)
+	session.AddHandler(bot.guildCreate)
 	session.AddHandler(bot.interactionCreate)
 
 	// Set intents
@@ -51,27 +85,77 @@ func NewBot(cfg *config.Config) (*Bot, error) {
 	return bot, nil
 }
 
+// newRecommender builds the AI recommender for find from the configured provider order,
+// skipping providers without an API key; it returns nil when no provider is available
+func newRecommender(cfg *config.Config) ai.Recommender {
+	var providers []ai.Recommender
+	seen := make(map[string]bool)
+
+	for _, name := range append([]string{cfg.AIProvider}, cfg.AIFallback...) {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		switch {
+		case name == ai.ProviderOpenAI && cfg.IsOpenAIEnabled:
+			providers = append(providers, openai.NewRecommender(cfg.OpenAIAPIKey, cfg.OpenAIModel))
+		case name == ai.ProviderClaude && cfg.IsClaudeEnabled:
+			providers = append(providers, claude.NewRecommender(cfg.ClaudeAPIKey, cfg.ClaudeModel))
+		}
+	}
+
+	switch len(providers) {
+	case 0:
+		return nil
+	case 1:
+		return providers[0]
+	default:
+		return ai.NewChain(providers...)
+	}
+}
+
 // Start starts the bot
 func (b *Bot) Start() error {
 	if err := b.session.Open(); err != nil {
 		return fmt.Errorf("failed to open Discord session: %w", err)
 	}
+
+	// Digests are posted through the session, so only start once it is open
+	b.notificationService.StartReconciler()
+	b.notificationService.StartStorageSync()
+	b.notificationService.StartDeliveryQueue()
+	b.notificationService.StartCatchUp()
+	b.digestScheduler.Start()
+	b.autoNotifier.Start()
+	b.startCallbackServer()
+	b.startMetricsServer()
 	return nil
 }
 
 // Stop stops the bot
 func (b *Bot) Stop() {
+	if b.autoNotifier != nil {
+		b.autoNotifier.Stop()
+	}
 	if b.notificationService != nil {
 		b.notificationService.Cleanup()
 	}
+	if b.digestScheduler != nil {
+		b.digestScheduler.Stop()
+	}
+	b.stopCallbackServer()
+	b.stopMetricsServer()
 	if b.session != nil {
 		if err := b.session.Close(); err != nil {
 			log.Printf("Error closing Discord session: %v", err)
 		}
 	}
-	// Close Redis connection
-	if err := redis.Close(); err != nil {
-		log.Printf("Error closing Redis connection: %v", err)
+	// Close storage last, so the memory backend's final snapshot includes everything above
+	if b.store != nil {
+		if err := b.store.Close(); err != nil {
+			log.Printf("Error closing storage: %v", err)
+		}
 	}
 }
 
@@ -79,22 +163,26 @@ func (b *Bot) Stop() {
 func (b *Bot) ready(s *discordgo.Session, event *discordgo.Ready) {
 	log.Printf("Logged in as %s!", s.State.User.Username)
 
-	// Get all commands from the commands package
-	commands := commands.GetAllCommands(b.config)
-
-	// Register commands to the first guild (for testing)
-	// In production, you might want to register globally
-	guilds := s.State.Guilds
-	if len(guilds) > 0 {
-		for _, cmd := range commands {
-			_, err := s.ApplicationCommandCreate(s.State.User.ID, guilds[0].ID, cmd)
-			if err != nil {
-				log.Printf("Failed to register command %s: %v", cmd.Name, err)
-			} else {
-				log.Printf("Successfully registered command: %s", cmd.Name)
-			}
+	// Guild-scoped commands are synced from guildCreate, which fires for every guild after ready
+	if !b.registrar.IsGlobal() {
+		return
+	}
+
+	if err := b.registrar.SyncGlobal(); err != nil {
+		log.Printf("Failed to register global commands: %v", err)
+	}
+}
+
+// guildCreate is called for every guild on startup and whenever the bot joins a new guild
+func (b *Bot) guildCreate(s *discordgo.Session, event *discordgo.GuildCreate) {
+	if !b.registrar.GuildAllowed(event.ID) {
+		if err := b.registrar.ClearGuild(event.ID); err != nil {
+			log.Printf("Failed to remove commands from guild %s: %v", event.ID, err)
 		}
-	} else {
-		log.Println("Warning: No guilds found. Commands may not be registered.")
+		return
+	}
+
+	if err := b.registrar.SyncGuild(event.ID); err != nil {
+		log.Printf("Failed to register commands for guild %s: %v", event.ID, err)
 	}
 }
@@ -0,0 +1,280 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"discord-anime-bot/internal/services/settings"
+	"discord-anime-bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// maxAutocompleteChoices is Discord's limit on choices per autocomplete response
+	maxAutocompleteChoices = 25
+	// maxChoiceNameLength is Discord's limit on the length of a choice name
+	maxChoiceNameLength = 100
+	// autocompleteTimeout keeps lookups inside Discord's 3 second response window
+	autocompleteTimeout = 2500 * time.Millisecond
+)
+
+// handleAutocomplete suggests anime for the focused "id" option of /anime subcommands
+func (b *Bot) handleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	if data.Name != "anime" || len(data.Options) == 0 {
+		return
+	}
+
+	subcommand := data.Options[0]
+	var focused *discordgo.ApplicationCommandInteractionDataOption
+	var action string
+	for _, option := range subcommand.Options {
+		if option.Focused {
+			focused = option
+		}
+		if option.Name == "action" {
+			action = option.StringValue()
+		}
+	}
+	if focused == nil || focused.Name != "id" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), autocompleteTimeout)
+	defer cancel()
+
+	query := strings.TrimSpace(focused.StringValue())
+	userID := interactionUser(i).ID
+	guild := b.guildSettings(i.GuildID)
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	switch {
+	case subcommand.Name == "notify" && action == "cancel":
+		choices = b.notificationChoices(ctx, b.notificationService.GetUserNotifications(userID), query, guild)
+	case subcommand.Name == "watchlist" && action != "" && action != "add":
+		animeIDs, err := b.anilist.GetUserWatchlist(userID)
+		if err != nil {
+			log.Printf("Error getting watchlist for autocomplete: %v", err)
+		}
+		choices = b.ownedAnimeChoices(ctx, animeIDs, query, guild)
+	default:
+		choices = b.searchAnimeChoices(ctx, query, guild)
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to respond to autocomplete: %v", err)
+	}
+}
+
+// searchAnimeChoices returns AniList search results for a partially typed title
+func (b *Bot) searchAnimeChoices(ctx context.Context, query string, guild *settings.Settings) []*discordgo.ApplicationCommandOptionChoice {
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	if query == "" {
+		return choices
+	}
+
+	searchResults, err := b.anilist.SearchAnime(ctx, query, 1, maxAutocompleteChoices)
+	if err != nil {
+		log.Printf("Error searching anime for autocomplete: %v", err)
+		return choices
+	}
+
+	for _, anime := range searchResults.Data.Page.Media {
+		choices = append(choices, animeChoice(anime.ID, guild.Title(anime.Title), anime.SeasonYear, anime.Format))
+	}
+
+	return choices
+}
+
+// ownedAnimeChoices returns the anime from a user's own notifications or watchlist whose title matches the query
+func (b *Bot) ownedAnimeChoices(ctx context.Context, animeIDs []int, query string, guild *settings.Settings) []*discordgo.ApplicationCommandOptionChoice {
+	details, err := b.anilist.GetAnimeByIDs(ctx, animeIDs)
+	if err != nil {
+		log.Printf("Error getting anime for autocomplete: %v", err)
+	}
+
+	query = strings.ToLower(query)
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, animeID := range animeIDs {
+		var choice *discordgo.ApplicationCommandOptionChoice
+		if anime, ok := details[animeID]; ok {
+			choice = animeChoice(anime.ID, guild.Title(anime.Title), anime.SeasonYear, anime.Format)
+		} else {
+			choice = &discordgo.ApplicationCommandOptionChoice{
+				Name:  fmt.Sprintf("Anime ID %d", animeID),
+				Value: strconv.Itoa(animeID),
+			}
+		}
+
+		if choiceMatches(choice, animeID, query) {
+			choices = append(choices, choice)
+		}
+	}
+
+	return sortedChoices(choices)
+}
+
+// notificationChoices returns a user's notifications whose title matches the query, labelled with where each is delivered
+// Their values carry the channel as well as the anime, so a notification set up in another channel or a DM can be cancelled
+func (b *Bot) notificationChoices(ctx context.Context, notifications []*types.NotificationEntry, query string, guild *settings.Settings) []*discordgo.ApplicationCommandOptionChoice {
+	animeIDs := make([]int, 0, len(notifications))
+	for _, notification := range notifications {
+		animeIDs = append(animeIDs, notification.AnimeID)
+	}
+	details, err := b.anilist.GetAnimeByIDs(ctx, animeIDs)
+	if err != nil {
+		log.Printf("Error getting anime for autocomplete: %v", err)
+	}
+
+	query = strings.ToLower(query)
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, notification := range notifications {
+		title, year, format := fmt.Sprintf("Anime ID %d", notification.AnimeID), 0, ""
+		if anime, ok := details[notification.AnimeID]; ok {
+			title, year, format = guild.Title(anime.Title), anime.SeasonYear, anime.Format
+		}
+
+		choice := animeChoice(notification.AnimeID, title, year, format, b.notificationLocation(notification))
+		choice.Value = fmt.Sprintf("%d%s%s", notification.AnimeID, notificationChoiceSeparator, notification.ChannelID)
+		if choiceMatches(choice, notification.AnimeID, query) {
+			choices = append(choices, choice)
+		}
+	}
+
+	return sortedChoices(choices)
+}
+
+// notificationChoiceSeparator separates the anime and channel IDs in the value of a notification choice
+const notificationChoiceSeparator = ":"
+
+// parseNotificationChoice splits the value of a notification choice into the anime query and channel ID
+// Values typed by hand, including titles containing the separator, are returned unchanged with no channel
+func parseNotificationChoice(value string) (string, string) {
+	animeID, channelID, found := strings.Cut(strings.TrimSpace(value), notificationChoiceSeparator)
+	if !found {
+		return value, ""
+	}
+	if _, err := strconv.Atoi(animeID); err != nil {
+		return value, ""
+	}
+	if _, err := strconv.ParseUint(channelID, 10, 64); err != nil {
+		return value, ""
+	}
+	return animeID, channelID
+}
+
+// notificationLocation describes where a notification is delivered
+func (b *Bot) notificationLocation(notification *types.NotificationEntry) string {
+	if notification.DM {
+		return "DM"
+	}
+	if channel, err := b.session.State.Channel(notification.ChannelID); err == nil && channel.Name != "" {
+		return "#" + channel.Name
+	}
+	return "channel " + notification.ChannelID
+}
+
+// choiceMatches reports whether a lowercased query appears in a choice's name or anime ID
+func choiceMatches(choice *discordgo.ApplicationCommandOptionChoice, animeID int, query string) bool {
+	return query == "" || strings.Contains(strings.ToLower(choice.Name), query) || strings.Contains(strconv.Itoa(animeID), query)
+}
+
+// sortedChoices sorts choices by name and keeps as many as Discord accepts
+func sortedChoices(choices []*discordgo.ApplicationCommandOptionChoice) []*discordgo.ApplicationCommandOptionChoice {
+	slices.SortFunc(choices, func(a, b *discordgo.ApplicationCommandOptionChoice) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if len(choices) > maxAutocompleteChoices {
+		choices = choices[:maxAutocompleteChoices]
+	}
+
+	return choices
+}
+
+// animeChoice builds a "title (year, format, extra...)" choice whose value is the AniList ID
+func animeChoice(animeID int, title string, year int, format string, extra ...string) *discordgo.ApplicationCommandOptionChoice {
+	var details []string
+	if year > 0 {
+		details = append(details, strconv.Itoa(year))
+	}
+	if format != "" {
+		details = append(details, format)
+	}
+	details = append(details, extra...)
+
+	suffix := ""
+	if len(details) > 0 {
+		suffix = fmt.Sprintf(" (%s)", strings.Join(details, ", "))
+	}
+
+	// Truncate the title rather than the year/format details
+	if maxTitle := maxChoiceNameLength - len([]rune(suffix)); len([]rune(title)) > maxTitle {
+		title = string([]rune(title)[:maxTitle-1]) + "…"
+	}
+
+	return &discordgo.ApplicationCommandOptionChoice{
+		Name:  title + suffix,
+		Value: strconv.Itoa(animeID),
+	}
+}
+
+var (
+	// errInvalidAnimeID is returned for an empty or non-positive anime ID
+	errInvalidAnimeID = errors.New("invalid anime ID")
+	// errAnimeNotFound is returned when a typed title matches no anime on AniList
+	errAnimeNotFound = errors.New("no anime found")
+)
+
+// resolveAnimeID turns an "id" option value into an AniList ID
+// Autocomplete fills in the numeric ID, but a title typed without picking a suggestion
+// is resolved to the best AniList search match
+func (b *Bot) resolveAnimeID(ctx context.Context, value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, errInvalidAnimeID
+	}
+
+	if animeID, err := strconv.Atoi(value); err == nil {
+		if animeID <= 0 {
+			return 0, errInvalidAnimeID
+		}
+		return animeID, nil
+	}
+
+	searchResults, err := b.anilist.SearchAnime(ctx, value, 1, 1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to search anime %q: %w", value, err)
+	}
+	if len(searchResults.Data.Page.Media) == 0 {
+		return 0, errAnimeNotFound
+	}
+
+	return searchResults.Data.Page.Media[0].ID, nil
+}
+
+// resolveAnimeIDMessage returns the message shown to the user when resolveAnimeID fails for value
+func resolveAnimeIDMessage(err error, value string) string {
+	switch {
+	case errors.Is(err, errInvalidAnimeID):
+		return "Please provide a valid anime ID."
+	case errors.Is(err, errAnimeNotFound):
+		return fmt.Sprintf("No anime found for query: \"%s\"", strings.TrimSpace(value))
+	default:
+		log.Printf("Error resolving anime: %v", err)
+		return "An error occurred while searching for anime."
+	}
+}
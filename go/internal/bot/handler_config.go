@@ -0,0 +1,302 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"discord-anime-bot/internal/services/digest"
+	"discord-anime-bot/internal/services/settings"
+	"discord-anime-bot/internal/utils"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// guildSettings returns the settings of a guild, falling back to the defaults when they cannot be loaded
+func (b *Bot) guildSettings(guildID string) *settings.Settings {
+	guild, err := b.guilds.Get(context.Background(), guildID)
+	if err != nil {
+		log.Printf("Error loading guild settings: %v", err)
+	}
+	return guild
+}
+
+// handleConfigCommand handles the /anime-config admin command
+// Access is restricted by the command's DefaultMemberPermissions, which server admins can adjust
+func (b *Bot) handleConfigCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Settings are only visible to the admin changing them
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to defer interaction response: %v", err)
+		return
+	}
+
+	if i.GuildID == "" {
+		b.respondWithError(s, i, "Server settings can only be changed inside a server.")
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		b.respondWithError(s, i, "No subcommand provided")
+		return
+	}
+	subcommand := options[0]
+
+	ctx := context.Background()
+	guild, err := b.guilds.Get(ctx, i.GuildID)
+	if err != nil {
+		// Never overwrite stored settings with defaults because of a failed read
+		log.Printf("Error loading guild settings: %v", err)
+		b.respondWithError(s, i, "Failed to load the server settings. Please try again later.")
+		return
+	}
+
+	switch subcommand.Name {
+	case "view":
+		b.sendConfigEmbed(s, i, guild, "Server Settings")
+		return
+	case "deliveries":
+		b.sendFailedDeliveries(s, i)
+		return
+	case "reset":
+		if err := b.guilds.Reset(ctx, i.GuildID); err != nil {
+			log.Printf("Error resetting guild settings: %v", err)
+			b.respondWithError(s, i, "Failed to reset the server settings.")
+			return
+		}
+		b.sendConfigEmbed(s, i, &settings.Settings{GuildID: i.GuildID}, "Settings Reset")
+		return
+	case "channel":
+		guild.NotificationChannelID = ""
+		if len(subcommand.Options) > 0 {
+			guild.NotificationChannelID = subcommand.Options[0].ChannelValue(nil).ID
+		}
+	case "role":
+		guild.PingRoleID = ""
+		if len(subcommand.Options) > 0 {
+			guild.PingRoleID = subcommand.Options[0].RoleValue(nil, i.GuildID).ID
+		}
+	case "language":
+		guild.TitleLanguage = subcommand.Options[0].StringValue()
+	case "timezone":
+		timezone := strings.TrimSpace(subcommand.Options[0].StringValue())
+		if _, err := time.LoadLocation(timezone); err != nil || timezone == "" || strings.EqualFold(timezone, "local") {
+			b.respondWithError(s, i, fmt.Sprintf("Unknown time zone %q. Use an IANA name such as Europe/Berlin, America/New_York or Asia/Tokyo.", timezone))
+			return
+		}
+		guild.Timezone = timezone
+	case "command":
+		var name string
+		var enabled bool
+		for _, option := range subcommand.Options {
+			switch option.Name {
+			case "name":
+				name = option.StringValue()
+			case "enabled":
+				enabled = option.BoolValue()
+			}
+		}
+		guild.SetCommandEnabled(name, enabled)
+	case "find":
+		guild.AIFindDisabled = !subcommand.Options[0].BoolValue()
+	case "digest":
+		if message := applyDigestOptions(guild, i, subcommand.Options); message != "" {
+			b.respondWithError(s, i, message)
+			return
+		}
+	default:
+		b.respondWithError(s, i, "Unknown subcommand")
+		return
+	}
+
+	if err := b.guilds.Save(ctx, guild); err != nil {
+		log.Printf("Error saving guild settings: %v", err)
+		b.respondWithError(s, i, "Failed to save the server settings.")
+		return
+	}
+
+	b.sendConfigEmbed(s, i, guild, "Settings Updated")
+}
+
+// sendConfigEmbed edits the deferred response with an overview of a guild's settings
+func (b *Bot) sendConfigEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, guild *settings.Settings, title string) {
+	channel := "Channel where each alert was set up"
+	if guild.NotificationChannelID != "" {
+		channel = fmt.Sprintf("<#%s>", guild.NotificationChannelID)
+	}
+
+	role := "None"
+	if guild.PingRoleID != "" {
+		role = fmt.Sprintf("<@&%s>", guild.PingRoleID)
+	}
+
+	language := guild.TitleLanguage
+	if language == "" {
+		language = settings.TitleLanguageEnglish
+	}
+
+	timezone := guild.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	disabled := "None"
+	if len(guild.DisabledCommands) > 0 {
+		disabled = strings.Join(guild.DisabledCommands, ", ")
+	}
+
+	digestSchedule := "Off"
+	if guild.DigestEnabled() {
+		hour, minute := guild.DigestClock()
+		when := fmt.Sprintf("daily at %02d:%02d", hour, minute)
+		if guild.DigestFrequency == settings.DigestWeekly {
+			when = fmt.Sprintf("%ss at %02d:%02d", guild.DigestWeekday, hour, minute)
+		}
+		next := digest.NextRun(guild, time.Now())
+		digestSchedule = fmt.Sprintf("In <#%s> %s (next %s)", guild.DigestChannelID, when, utils.FormatRelativeTimestamp(next))
+	}
+
+	find := "Allowed"
+	if guild.AIFindDisabled {
+		find = "Disabled"
+	}
+	if b.recommender == nil {
+		find += " (no AI provider configured)"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: title,
+		Color: 0x0099FF,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Alert Channel", Value: channel, Inline: true},
+			{Name: "Ping Role", Value: role, Inline: true},
+			{Name: "Title Language", Value: language, Inline: true},
+			{Name: "Time Zone", Value: timezone, Inline: true},
+			{Name: "AI Find", Value: find, Inline: true},
+			{Name: "Disabled Commands", Value: disabled, Inline: true},
+			{Name: "Airing Digest", Value: digestSchedule, Inline: false},
+		},
+	}
+
+	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+	if err != nil {
+		log.Printf("Failed to edit interaction response: %v", err)
+	}
+}
+
+const (
+	// maxFailedDeliveriesShown is how many failed alerts /anime-config deliveries lists
+	maxFailedDeliveriesShown = 10
+	// maxDeliveryErrorLength is how much of each failure's error is shown
+	maxDeliveryErrorLength = 300
+)
+
+// sendFailedDeliveries edits the deferred response with the guild's most recent alerts that could not be delivered
+func (b *Bot) sendFailedDeliveries(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	deliveries, err := b.notificationService.FailedDeliveries(context.Background(), i.GuildID)
+	if err != nil {
+		log.Printf("Error loading failed deliveries: %v", err)
+		b.respondWithError(s, i, "Failed to load the failed deliveries. Please try again later.")
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Failed Deliveries",
+		Description: "No episode alerts failed to deliver recently.",
+		Color:       0x0099FF,
+	}
+	if len(deliveries) > 0 {
+		embed.Description = fmt.Sprintf("%d episode alerts could not be delivered recently, newest first.", len(deliveries))
+		embed.Color = 0xFF0000
+	}
+
+	guild := b.guildSettings(i.GuildID)
+	for _, delivery := range deliveries[:min(len(deliveries), maxFailedDeliveriesShown)] {
+		title := fmt.Sprintf("Anime %d", delivery.AnimeID)
+		if delivery.Title != nil {
+			title = guild.Title(*delivery.Title)
+		}
+
+		channel := "Unknown channel"
+		if delivery.ChannelID != "" {
+			channel = fmt.Sprintf("<#%s>", delivery.ChannelID)
+		}
+
+		reason := "gave up after retrying"
+		if delivery.Permanent {
+			reason = "cannot be fixed by retrying"
+		}
+
+		// Keep long errors from pushing the field over Discord's 1024 character limit
+		lastError := delivery.LastError
+		if runes := []rune(lastError); len(runes) > maxDeliveryErrorLength {
+			lastError = string(runes[:maxDeliveryErrorLength-1]) + "…"
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: fmt.Sprintf("%s · Episode %d", title, delivery.Episode),
+			Value: fmt.Sprintf("%s · %d users · %d attempts, %s\n%s\nFailed %s",
+				channel, len(delivery.Entries), delivery.Attempts, reason, lastError,
+				utils.FormatRelativeTimestamp(time.Unix(delivery.FailedAt, 0))),
+		})
+	}
+	if len(deliveries) > maxFailedDeliveriesShown {
+		embed.Footer = &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Showing the %d most recent of %d", maxFailedDeliveriesShown, len(deliveries)),
+		}
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+	if err != nil {
+		log.Printf("Failed to edit interaction response: %v", err)
+	}
+}
+
+// applyDigestOptions updates a guild's digest schedule from the digest subcommand options
+// It returns a user-facing message when an option is invalid
+func applyDigestOptions(guild *settings.Settings, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) string {
+	channelID := i.ChannelID
+	weekday := time.Monday
+	clock := settings.DefaultDigestTime
+	var frequency string
+
+	for _, option := range options {
+		switch option.Name {
+		case "frequency":
+			frequency = option.StringValue()
+		case "channel":
+			channelID = option.ChannelValue(nil).ID
+		case "time":
+			clock = strings.TrimSpace(option.StringValue())
+		case "weekday":
+			weekday = time.Weekday(option.IntValue())
+		}
+	}
+
+	if frequency == "off" {
+		guild.DigestFrequency = ""
+		return ""
+	}
+
+	if _, err := time.Parse("15:04", clock); err != nil {
+		return fmt.Sprintf("Invalid time %q. Use 24-hour HH:MM, e.g. 09:00 or 18:30.", clock)
+	}
+
+	guild.DigestFrequency = frequency
+	guild.DigestChannelID = channelID
+	guild.DigestTime = clock
+	guild.DigestWeekday = weekday
+	return ""
+}
@@ -1,20 +1,19 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
 	"strings"
 
-	"discord-anime-bot/internal/services/anilist"
-
 	"github.com/bwmarrin/discordgo"
 )
 
 // handleFindCommand handles the anime find subcommand
 func (b *Bot) handleFindCommand(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
-	if !b.config.IsOpenAIEnabled {
-		b.respondWithError(s, i, "The find command is disabled because OpenAI API key is not configured. Please set the OPENAI_API_KEY environment variable to use AI-powered anime search.")
+	if b.recommender == nil {
+		b.respondWithError(s, i, "The find command is disabled because no AI provider is configured. Please set the OPENAI_API_KEY or CLAUDE_API_KEY environment variable to use AI-powered anime search.")
 		return
 	}
 
@@ -30,7 +29,7 @@ func (b *Bot) handleFindCommand(s *discordgo.Session, i *discordgo.InteractionCr
 	}
 
 	// Find anime using AI (OpenAI or Claude, based on config)
-	matches, err := anilist.FindAnimeWithDetails(prompt, b.config)
+	matches, attribution, err := b.anilist.FindAnimeWithDetails(context.Background(), prompt, b.recommender)
 	if err != nil {
 		log.Printf("Error finding anime: %v", err)
 		b.respondWithError(s, i, "An error occurred while searching for anime.")
@@ -46,11 +45,8 @@ func (b *Bot) handleFindCommand(s *discordgo.Session, i *discordgo.InteractionCr
 	bestMatch := matches[0]
 	anime := bestMatch.Anime
 
-	// Build the title, preferring English over Romaji
-	title := anime.Title.Romaji
-	if anime.Title.English != nil && *anime.Title.English != "" {
-		title = *anime.Title.English
-	}
+	guild := b.guildSettings(i.GuildID)
+	title := guild.Title(anime.Title)
 
 	embed := &discordgo.MessageEmbed{
 		Title:       fmt.Sprintf("🎯 %s", title),
@@ -69,10 +65,17 @@ func (b *Bot) handleFindCommand(s *discordgo.Session, i *discordgo.InteractionCr
 			{Name: "AI Confidence", Value: fmt.Sprintf("%d%%", int(math.Round(bestMatch.Confidence*100))), Inline: true},
 		},
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Powered by GPT-5 + AniList",
+			Text: fmt.Sprintf("Powered by %s + AniList", attribution),
 		},
 	}
 
+	if !bestMatch.Verified {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "⚠️ Unverified Match",
+			Value: fmt.Sprintf("The AI suggested \"%s\"; this is the closest AniList title (%d%% similar).", bestMatch.RecommendedTitle, int(math.Round(bestMatch.MatchScore*100))),
+		})
+	}
+
 	responseText := fmt.Sprintf("🤖 **AI Found Anime Based on:** \"%s\"\n\n", prompt)
 
 	if len(matches) > 1 {
@@ -82,12 +85,12 @@ func (b *Bot) handleFindCommand(s *discordgo.Session, i *discordgo.InteractionCr
 			if i >= 2 { // Only show top 3 total (best + 2 others)
 				break
 			}
-			matchTitle := match.Anime.Title.Romaji
-			if match.Anime.Title.English != nil && *match.Anime.Title.English != "" {
-				matchTitle = *match.Anime.Title.English
+			matchTitle := guild.Title(match.Anime.Title)
+			line := fmt.Sprintf("%d. **%s** (%d%% match)", i+2, matchTitle, int(math.Round(match.Confidence*100)))
+			if !match.Verified {
+				line += " _(unverified)_"
 			}
-			otherMatches = append(otherMatches, fmt.Sprintf("%d. **%s** (%d%% match)",
-				i+2, matchTitle, int(math.Round(match.Confidence*100))))
+			otherMatches = append(otherMatches, line)
 		}
 		responseText += strings.Join(otherMatches, "\n")
 	}
@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/bwmarrin/discordgo"
@@ -15,17 +16,33 @@ func (b *Bot) handleHelpCommand(s *discordgo.Session, i *discordgo.InteractionCr
 		"**/anime search <query>**: Search for anime by title",
 		"**/anime release**: Get currently releasing anime",
 		"**/anime season <season> [year]**: Get all anime from a specific season and year",
+		"**/anime schedule [day] [hours] [timezone]**: Show episodes airing on a day or within the next hours",
 		"**/anime next <id>**: Get next episode information for an anime",
 		"**/anime notify add <id>**: Set notification for next episode",
+		"**/anime notify subscribe <id>**: Get notified for every episode until the anime finishes",
 		"**/anime notify list**: List your active episode notifications",
 		"**/anime notify cancel <id>**: Cancel notification for an anime",
+		"**/anime notify auto [channel] [dm]**: Get alerts for every airing anime you're planning or watching on your watchlist",
+		"**/anime notify auto-off**: Stop watchlist auto-notify",
 		"**/anime watchlist add <id>**: Add an anime to your personal watchlist",
 		"**/anime watchlist list**: Show your personal anime watchlist (only visible to you)",
 		"**/anime watchlist remove <id>**: Remove an anime from your personal watchlist",
+		"**/anime watchlist watched <id>**: Mark the next episode of an anime as watched",
+		"**/anime watchlist progress <id> <episodes>**: Set how many episodes you've watched",
+		"**/anime watchlist status <id> <status>**: Change an entry to planning, watching, completed, dropped or paused",
+		"**/anime watchlist score <id> <score>**: Score an anime out of 10",
 	}
-	if b.config.IsOpenAIEnabled {
-		helpLines = append(helpLines, "**/anime find <prompt>**: Find anime by description using AI")
+	if b.anilist.LinkingEnabled() {
+		helpLines = append(helpLines,
+			"**/anime link [unlink]**: Link your AniList account so watchlist changes sync to AniList",
+			"**/anime watchlist import**: Copy your AniList list into your watchlist",
+			"**/anime watchlist export**: Push your watchlist to your AniList list",
+		)
 	}
+	if b.recommender != nil {
+		helpLines = append(helpLines, fmt.Sprintf("**/anime find <prompt>**: Find anime by description using AI (%s)", b.recommender.Name()))
+	}
+	helpLines = append(helpLines, "", "**/anime-config**: Configure the bot for this server (requires Manage Server)")
 	helpText := ""
 	for _, line := range helpLines {
 		helpText += line + "\n"
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"discord-anime-bot/internal/services/anilist"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleLinkCommand handles the anime link subcommand
+// Responses are ephemeral since they carry a personal authorization URL
+func (b *Bot) handleLinkCommand(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var action string
+	for _, option := range options {
+		if option.Name == "action" {
+			action = option.StringValue()
+		}
+	}
+
+	userID := interactionUser(i).ID
+	if action == "unlink" {
+		b.handleUnlinkCommand(s, i, userID)
+		return
+	}
+
+	authURL, err := b.anilist.AuthorizationURL(context.Background(), userID)
+	if err != nil {
+		log.Printf("Error creating AniList authorization URL: %v", err)
+		b.respondWithError(s, i, "Failed to start AniList linking. Please try again later.")
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Link your AniList account",
+		Description: fmt.Sprintf("[Authorize the bot on AniList](%s) to sync your watchlist. The link works once and expires in 10 minutes.", authURL),
+		Color:       0x02A9FF,
+	}
+
+	account, err := b.anilist.GetLinkedAccount(userID)
+	switch {
+	case err == nil:
+		embed.Title = "AniList account linked"
+		embed.Description = fmt.Sprintf("Linked to **[%s](%s)** since <t:%d:D>.\n\nTo link a different account, [authorize it on AniList](%s). Use `/anime link action:unlink` to remove the link.", account.Name, account.SiteURL, account.LinkedAt, authURL)
+		if account.ExpiresAt > 0 {
+			embed.Footer = &discordgo.MessageEmbedFooter{Text: "Access expires " + time.Unix(account.ExpiresAt, 0).Format("January 2, 2006")}
+		}
+	case !errors.Is(err, anilist.ErrNotLinked):
+		log.Printf("Error getting linked AniList account of user %s: %v", userID, err)
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+	if err != nil {
+		log.Printf("Failed to edit interaction response: %v", err)
+	}
+}
+
+// handleUnlinkCommand removes the user's linked AniList account
+func (b *Bot) handleUnlinkCommand(s *discordgo.Session, i *discordgo.InteractionCreate, userID string) {
+	if _, err := b.anilist.GetLinkedAccount(userID); err != nil {
+		b.respondWithError(s, i, "You have no linked AniList account.")
+		return
+	}
+
+	msg := "Unlinked your AniList account. Your watchlist stays in Discord but is no longer synced."
+	if err := b.anilist.Unlink(userID); err != nil {
+		log.Printf("Error unlinking AniList account of user %s: %v", userID, err)
+		msg = "Failed to unlink your AniList account"
+	}
+	if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg}); err != nil {
+		log.Printf("Failed to edit interaction response: %v", err)
+	}
+}
+
+// syncWatchlistEntry pushes a changed watchlist entry to the user's linked AniList account
+// and returns a note for the reply; users without a linked account get no note
+func (b *Bot) syncWatchlistEntry(userID string, animeID int) string {
+	entry, err := b.anilist.GetWatchlistEntry(userID, animeID)
+	if err != nil || entry == nil {
+		return ""
+	}
+
+	err = b.anilist.SyncWatchlistEntry(context.Background(), userID, entry)
+	switch {
+	case err == nil:
+		return "\nSynced to AniList."
+	case errors.Is(err, anilist.ErrNotLinked):
+		return ""
+	default:
+		log.Printf("Error syncing anime %d to AniList for user %s: %v", animeID, userID, err)
+		return "\n⚠️ Couldn't sync this change to AniList."
+	}
+}
@@ -1,20 +1,61 @@
 package bot
 
 import (
+	"fmt"
 	"log"
+	"strings"
+
+	"discord-anime-bot/internal/commands/admin"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-// interactionCreate handles slash command interactions
+// interactionCreate routes incoming interactions by type
 func (b *Bot) interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if i.ApplicationCommandData().Name != "anime" {
-		return
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		b.handleApplicationCommand(s, i)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		b.handleAutocomplete(s, i)
+	case discordgo.InteractionMessageComponent:
+		b.handleMessageComponent(s, i)
+	}
+}
+
+// handleMessageComponent routes button presses and other message component interactions
+func (b *Bot) handleMessageComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	customID := i.MessageComponentData().CustomID
+	switch {
+	case strings.HasPrefix(customID, paginationCustomIDPrefix+":"):
+		b.handlePaginationComponent(s, i)
+	default:
+		log.Printf("Unhandled message component: %s", customID)
+	}
+}
+
+// handleApplicationCommand routes slash command interactions by command name
+func (b *Bot) handleApplicationCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	switch i.ApplicationCommandData().Name {
+	case "anime":
+		b.handleAnimeCommand(s, i)
+	case admin.ConfigCommandName:
+		b.handleConfigCommand(s, i)
 	}
+}
+
+// handleAnimeCommand handles /anime subcommands
+func (b *Bot) handleAnimeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
 
 	// Defer the response to give us more time to process
+	// link replies carry a personal authorization URL, so only the user may see them
+	var flags discordgo.MessageFlags
+	if len(options) > 0 && options[0].Name == "link" {
+		flags = discordgo.MessageFlagsEphemeral
+	}
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: flags},
 	})
 	if err != nil {
 		log.Printf("Failed to defer interaction response: %v", err)
@@ -22,17 +63,21 @@ func (b *Bot) interactionCreate(s *discordgo.Session, i *discordgo.InteractionCr
 	}
 
 	// Get the subcommand
-	options := i.ApplicationCommandData().Options
 	if len(options) == 0 {
 		b.respondWithError(s, i, "No subcommand provided")
 		return
 	}
 
 	subcommand := options[0]
+	if !b.guildSettings(i.GuildID).CommandEnabled(subcommand.Name) {
+		b.respondWithError(s, i, fmt.Sprintf("The %s command has been disabled on this server.", subcommand.Name))
+		return
+	}
+
 	switch subcommand.Name {
 	case "find":
-		if !b.config.IsOpenAIEnabled {
-			b.respondWithError(s, i, "The find command is disabled because OpenAI API key is not configured.")
+		if b.recommender == nil {
+			b.respondWithError(s, i, "The find command is disabled because no AI provider (OpenAI or Claude) is configured.")
 			return
 		}
 		b.handleFindCommand(s, i, subcommand.Options)
@@ -42,12 +87,16 @@ func (b *Bot) interactionCreate(s *discordgo.Session, i *discordgo.InteractionCr
 		b.handleReleaseCommand(s, i)
 	case "season":
 		b.handleSeasonCommand(s, i, subcommand.Options)
+	case "schedule":
+		b.handleScheduleCommand(s, i, subcommand.Options)
 	case "next":
 		b.handleNextCommand(s, i, subcommand.Options)
 	case "notify":
 		b.handleNotifyCommand(s, i, subcommand.Options)
 	case "watchlist":
 		b.handleWatchlistCommand(s, i, subcommand.Options)
+	case "link":
+		b.handleLinkCommand(s, i, subcommand.Options)
 	case "help":
 		b.handleHelpCommand(s, i)
 	default:
@@ -55,6 +104,34 @@ func (b *Bot) interactionCreate(s *discordgo.Session, i *discordgo.InteractionCr
 	}
 }
 
+// interactionUser returns the user who triggered an interaction
+// Member is only set for interactions inside guilds; DMs and group DMs carry User instead
+func interactionUser(i *discordgo.InteractionCreate) *discordgo.User {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+	return i.User
+}
+
+// canPostInChannel reports whether the bot can send messages to the interaction's channel later on,
+// which requires a guild channel in a guild the bot has been installed to
+// User-installed commands in other guilds and group DMs can only be answered through the interaction itself
+func canPostInChannel(i *discordgo.InteractionCreate) bool {
+	switch i.Context {
+	case discordgo.InteractionContextBotDM:
+		return true
+	case discordgo.InteractionContextPrivateChannel:
+		return false
+	}
+
+	// Older payloads omit the integration owners; those come from guild installs
+	if i.AuthorizingIntegrationOwners == nil {
+		return true
+	}
+	_, guildInstalled := i.AuthorizingIntegrationOwners[discordgo.ApplicationIntegrationGuildInstall]
+	return guildInstalled
+}
+
 // respondWithError responds to an interaction with an error message
 func (b *Bot) respondWithError(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
 	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
@@ -64,3 +141,28 @@ func (b *Bot) respondWithError(s *discordgo.Session, i *discordgo.InteractionCre
 		log.Printf("Failed to send error response: %v", err)
 	}
 }
+
+// respondEphemeral responds to an interaction with a message only the invoking user can see
+func (b *Bot) respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: message,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to send ephemeral response: %v", err)
+	}
+}
+
+// followupEphemeral sends a message only the user can see to an interaction that was already acknowledged
+func (b *Bot) followupEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
+	_, err := s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+		Content: message,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+	if err != nil {
+		log.Printf("Failed to send ephemeral followup: %v", err)
+	}
+}
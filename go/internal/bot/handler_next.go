@@ -1,11 +1,11 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
-	"discord-anime-bot/internal/services/anilist"
 	"discord-anime-bot/internal/utils"
 
 	"github.com/bwmarrin/discordgo"
@@ -18,24 +18,23 @@ func (b *Bot) handleNextCommand(s *discordgo.Session, i *discordgo.InteractionCr
 		return
 	}
 
-	animeID := int(options[0].Value.(float64))
-	if animeID <= 0 {
-		b.respondWithError(s, i, "Please provide a valid anime ID.")
+	animeQuery := options[0].StringValue()
+	animeID, err := b.resolveAnimeID(context.Background(), animeQuery)
+	if err != nil {
+		b.respondWithError(s, i, resolveAnimeIDMessage(err, animeQuery))
 		return
 	}
 
 	// Get anime details
-	anime, err := anilist.GetAnimeByID(animeID)
+	anime, err := b.anilist.GetAnimeByID(context.Background(), animeID)
 	if err != nil {
 		log.Printf("Error getting anime by ID %d: %v", animeID, err)
 		b.respondWithError(s, i, fmt.Sprintf("No anime found with ID %d.", animeID))
 		return
 	}
 
-	title := anime.Title.Romaji
-	if anime.Title.English != nil && *anime.Title.English != "" {
-		title = *anime.Title.English
-	}
+	guild := b.guildSettings(i.GuildID)
+	title := guild.Title(anime.Title)
 
 	embed := &discordgo.MessageEmbed{
 		Title: title,
@@ -62,6 +61,9 @@ func (b *Bot) handleNextCommand(s *discordgo.Session, i *discordgo.InteractionCr
 		airingDate := time.Unix(int64(anime.NextAiringEpisode.AiringAt), 0)
 		timeString := utils.FormatCountdown(anime.NextAiringEpisode.TimeUntilAiring)
 		formattedAirDate := utils.FormatAirDate(airingDate)
+		if guild.Timezone != "" {
+			formattedAirDate += fmt.Sprintf(" (%s server time)", utils.FormatZonedDateTime(airingDate, guild.Location()))
+		}
 
 		embed.Description = fmt.Sprintf("Episode %d airs in %s", anime.NextAiringEpisode.Episode, timeString)
 		embed.Fields = []*discordgo.MessageEmbedField{
@@ -1,12 +1,16 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 	"time"
 
-	"discord-anime-bot/internal/services/anilist"
+	"discord-anime-bot/internal/services/autonotify"
+	"discord-anime-bot/internal/services/settings"
+	"discord-anime-bot/internal/types"
 	"discord-anime-bot/internal/utils"
 
 	"github.com/bwmarrin/discordgo"
@@ -15,7 +19,9 @@ import (
 // handleNotifyCommand handles the anime notify command
 func (b *Bot) handleNotifyCommand(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
 	var action string
-	var animeID int
+	var animeQuery string
+	var dm bool
+	var channelID string
 
 	// Parse options
 	for _, option := range options {
@@ -23,18 +29,36 @@ func (b *Bot) handleNotifyCommand(s *discordgo.Session, i *discordgo.Interaction
 		case "action":
 			action = option.StringValue()
 		case "id":
-			animeID = int(option.IntValue())
+			animeQuery = option.StringValue()
+		case "dm":
+			dm = option.BoolValue()
+		case "channel":
+			channelID = option.ChannelValue(nil).ID
 		}
 	}
 
+	// Alerts can only be posted in channels the bot has been added to; everywhere else they go to DMs
+	if !canPostInChannel(i) {
+		dm = true
+	}
+
 	// If no action is specified, show the list
 	if action == "" {
 		b.handleNotifyListCommand(s, i)
 		return
 	}
 
+	switch action {
+	case "auto":
+		b.handleNotifyAutoCommand(s, i, channelID, dm)
+		return
+	case "auto-off":
+		b.handleNotifyAutoOffCommand(s, i)
+		return
+	}
+
 	// Validate that ID is provided for actions that require it
-	if (action == "add" || action == "cancel") && animeID == 0 {
+	if (action == "add" || action == "subscribe" || action == "cancel") && animeQuery == "" {
 		message := "Please provide an anime ID for this action."
 		_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 			Content: &message,
@@ -45,23 +69,37 @@ func (b *Bot) handleNotifyCommand(s *discordgo.Session, i *discordgo.Interaction
 		return
 	}
 
+	// Cancel suggestions also name the channel of the notification
+	var cancelChannelID string
+	if action == "cancel" {
+		animeQuery, cancelChannelID = parseNotificationChoice(animeQuery)
+	}
+
+	animeID, err := b.resolveAnimeID(context.Background(), animeQuery)
+	if err != nil {
+		b.respondWithError(s, i, resolveAnimeIDMessage(err, animeQuery))
+		return
+	}
+
 	switch action {
 	case "add":
-		b.handleNotifyAddCommand(s, i, animeID)
+		b.handleNotifyAddCommand(s, i, animeID, dm)
+	case "subscribe":
+		b.handleNotifySubscribeCommand(s, i, animeID, dm)
 	case "cancel":
-		b.handleNotifyCancelCommand(s, i, animeID)
+		b.handleNotifyCancelCommand(s, i, animeID, cancelChannelID)
 	default:
 		b.respondWithError(s, i, "Unknown notify action")
 	}
 }
 
 // handleNotifyAddCommand handles adding a notification
-func (b *Bot) handleNotifyAddCommand(s *discordgo.Session, i *discordgo.InteractionCreate, animeID int) {
-	userID := i.Member.User.ID
+func (b *Bot) handleNotifyAddCommand(s *discordgo.Session, i *discordgo.InteractionCreate, animeID int, dm bool) {
+	userID := interactionUser(i).ID
 	channelID := i.ChannelID
 
 	// Get next episode data
-	nextEpisode, err := anilist.GetNextEpisode(animeID)
+	nextEpisode, err := b.anilist.GetNextEpisode(context.Background(), animeID)
 	if err != nil {
 		log.Printf("Error getting next episode for anime %d: %v", animeID, err)
 		message := "Failed to get anime information"
@@ -86,7 +124,7 @@ func (b *Bot) handleNotifyAddCommand(s *discordgo.Session, i *discordgo.Interact
 	}
 
 	// Add notification
-	err = b.notificationService.AddNotification(animeID, channelID, userID, time.Unix(int64(nextEpisode.AiringAt), 0), nextEpisode.Episode)
+	err = b.notificationService.AddNotification(animeID, i.GuildID, channelID, userID, time.Unix(int64(nextEpisode.AiringAt), 0), nextEpisode.Episode, dm)
 	if err != nil {
 		log.Printf("Error adding notification: %v", err)
 		message := "Failed to add notification"
@@ -100,7 +138,7 @@ func (b *Bot) handleNotifyAddCommand(s *discordgo.Session, i *discordgo.Interact
 	}
 
 	// Get anime details for the response
-	anime, err := anilist.GetAnimeByID(animeID)
+	anime, err := b.anilist.GetAnimeByID(context.Background(), animeID)
 	if err != nil {
 		log.Printf("Error getting anime details: %v", err)
 		message := "Failed to get anime information"
@@ -114,17 +152,18 @@ func (b *Bot) handleNotifyAddCommand(s *discordgo.Session, i *discordgo.Interact
 	}
 
 	// Build title
-	title := anime.Title.Romaji
-	if anime.Title.English != nil && *anime.Title.English != "" {
-		title = *anime.Title.English
-	}
+	guild := b.guildSettings(i.GuildID)
+	title := guild.Title(anime.Title)
 
 	airingTime := time.Unix(int64(nextEpisode.AiringAt), 0)
 	relativeTime := utils.FormatRelativeTimestamp(airingTime)
 
+	description := fmt.Sprintf("You'll be notified when **Episode %d** of **%s** airs %s", nextEpisode.Episode, title, relativeTime)
+	description += deliveryNote(i, guild, dm)
+
 	embed := &discordgo.MessageEmbed{
 		Title:       "Notification Added",
-		Description: fmt.Sprintf("You'll be notified when **Episode %d** of **%s** airs %s", nextEpisode.Episode, title, relativeTime),
+		Description: description,
 		Color:       0x00FF00,
 		Timestamp:   airingTime.Format(time.RFC3339),
 	}
@@ -137,9 +176,59 @@ func (b *Bot) handleNotifyAddCommand(s *discordgo.Session, i *discordgo.Interact
 	}
 }
 
+// handleNotifySubscribeCommand handles subscribing to every episode of an anime
+func (b *Bot) handleNotifySubscribeCommand(s *discordgo.Session, i *discordgo.InteractionCreate, animeID int, dm bool) {
+	userID := interactionUser(i).ID
+	channelID := i.ChannelID
+
+	anime, err := b.anilist.GetAnimeByID(context.Background(), animeID)
+	if err != nil {
+		log.Printf("Error getting anime details for %d: %v", animeID, err)
+		b.respondWithError(s, i, "Failed to get anime information")
+		return
+	}
+
+	if anime.Status == "FINISHED" || anime.Status == "CANCELLED" {
+		b.respondWithError(s, i, "This anime has finished airing, so there are no episodes to subscribe to.")
+		return
+	}
+
+	err = b.notificationService.AddSubscription(animeID, i.GuildID, channelID, userID, anime.NextAiringEpisode, dm)
+	if err != nil {
+		log.Printf("Error adding subscription: %v", err)
+		b.respondWithError(s, i, "Failed to add subscription")
+		return
+	}
+
+	guild := b.guildSettings(i.GuildID)
+	title := guild.Title(anime.Title)
+
+	description := fmt.Sprintf("You'll be notified for every new episode of **%s** until it finishes airing.", title)
+	if anime.NextAiringEpisode != nil {
+		airingTime := time.Unix(int64(anime.NextAiringEpisode.AiringAt), 0)
+		description += fmt.Sprintf("\nNext up: **Episode %d** airs %s", anime.NextAiringEpisode.Episode, utils.FormatRelativeTimestamp(airingTime))
+	} else {
+		description += "\nThe next episode has no airing date yet; you'll be notified once it's scheduled and airs."
+	}
+	description += deliveryNote(i, guild, dm)
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Subscription Added",
+		Description: description,
+		Color:       0x00FF00,
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+	if err != nil {
+		log.Printf("Failed to edit interaction response: %v", err)
+	}
+}
+
 // handleNotifyListCommand handles listing user's notifications
 func (b *Bot) handleNotifyListCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	userID := i.Member.User.ID
+	userID := interactionUser(i).ID
 	notifications := b.notificationService.GetUserNotifications(userID)
 
 	if len(notifications) == 0 {
@@ -158,23 +247,36 @@ func (b *Bot) handleNotifyListCommand(s *discordgo.Session, i *discordgo.Interac
 		return
 	}
 
+	guild := b.guildSettings(i.GuildID)
 	var description strings.Builder
 	for _, notification := range notifications {
 		// Get anime details
-		anime, err := anilist.GetAnimeByID(notification.AnimeID)
+		anime, err := b.anilist.GetAnimeByID(context.Background(), notification.AnimeID)
 		if err != nil {
 			log.Printf("Error getting anime details for %d: %v", notification.AnimeID, err)
 			continue
 		}
 
-		title := anime.Title.Romaji
-		if anime.Title.English != nil && *anime.Title.English != "" {
-			title = *anime.Title.English
-		}
+		title := guild.Title(anime.Title)
 
-		airingTime := time.Unix(notification.AiringAt, 0)
-		relativeTime := utils.FormatRelativeTimestamp(airingTime)
-		description.WriteString(fmt.Sprintf("• **%s** - Episode %d airs %s (ID: %d)\n", title, notification.Episode, relativeTime, notification.AnimeID))
+		var line string
+		if notification.Episode == 0 {
+			line = fmt.Sprintf("• **%s** - Waiting for the next episode to be scheduled (ID: %d)", title, notification.AnimeID)
+		} else {
+			airingTime := time.Unix(notification.AiringAt, 0)
+			relativeTime := utils.FormatRelativeTimestamp(airingTime)
+			line = fmt.Sprintf("• **%s** - Episode %d airs %s (ID: %d)", title, notification.Episode, relativeTime, notification.AnimeID)
+		}
+		if notification.Recurring {
+			line += " · every episode"
+		}
+		if notification.DM {
+			line += " · via DM"
+		}
+		if notification.Auto {
+			line += " · from watchlist"
+		}
+		description.WriteString(line + "\n")
 	}
 
 	embed := &discordgo.MessageEmbed{
@@ -192,9 +294,38 @@ func (b *Bot) handleNotifyListCommand(s *discordgo.Session, i *discordgo.Interac
 }
 
 // handleNotifyCancelCommand handles cancelling a notification
-func (b *Bot) handleNotifyCancelCommand(s *discordgo.Session, i *discordgo.InteractionCreate, animeID int) {
-	userID := i.Member.User.ID
-	channelID := i.ChannelID
+// channelID is the channel the notification was set up in; when empty, the notification in the current
+// channel is cancelled, or the user's only notification for the anime elsewhere
+func (b *Bot) handleNotifyCancelCommand(s *discordgo.Session, i *discordgo.InteractionCreate, animeID int, channelID string) {
+	userID := interactionUser(i).ID
+
+	var matching []*types.NotificationEntry
+	for _, notification := range b.notificationService.GetUserNotifications(userID) {
+		if notification.AnimeID == animeID && (channelID == "" || notification.ChannelID == channelID) {
+			matching = append(matching, notification)
+		}
+	}
+	if channelID == "" {
+		channelID = i.ChannelID
+		if len(matching) > 1 && !slices.ContainsFunc(matching, func(notification *types.NotificationEntry) bool {
+			return notification.ChannelID == i.ChannelID
+		}) {
+			b.respondWithError(s, i, "You have notifications for this anime in several channels. Pick the one to cancel from the suggestions.")
+			return
+		}
+		if len(matching) == 1 {
+			channelID = matching[0].ChannelID
+		}
+	}
+
+	// Alerts added by watchlist auto-notify would come back on the next check, so remember the cancellation
+	for _, notification := range matching {
+		if notification.Auto && notification.ChannelID == channelID {
+			if err := b.autoNotifier.Mute(context.Background(), userID, animeID); err != nil {
+				log.Printf("Error muting auto-notify for anime %d: %v", animeID, err)
+			}
+		}
+	}
 
 	err := b.notificationService.RemoveNotification(animeID, channelID, userID)
 	if err != nil {
@@ -222,3 +353,105 @@ func (b *Bot) handleNotifyCancelCommand(s *discordgo.Session, i *discordgo.Inter
 		log.Printf("Failed to edit interaction response: %v", err)
 	}
 }
+
+// handleNotifyAutoCommand opts the user in to alerts for every airing anime on their watchlist
+// Alerts go to channelID, the current channel when empty, or to DMs
+func (b *Bot) handleNotifyAutoCommand(s *discordgo.Session, i *discordgo.InteractionCreate, channelID string, dm bool) {
+	ctx := context.Background()
+	userID := interactionUser(i).ID
+
+	if channelID != "" && dm {
+		message := "Choose either a channel or DMs for auto-notify alerts, not both."
+		if !canPostInChannel(i) {
+			message = "The bot can't post in channels here, so auto-notify alerts can only be sent to your DMs."
+		}
+		b.respondWithError(s, i, message)
+		return
+	}
+	if channelID == "" {
+		channelID = i.ChannelID
+	}
+
+	preference := &autonotify.Preference{
+		UserID:    userID,
+		GuildID:   i.GuildID,
+		ChannelID: channelID,
+		DM:        dm,
+		EnabledAt: time.Now().Unix(),
+	}
+	if err := b.autoNotifier.Enable(ctx, preference); err != nil {
+		log.Printf("Error enabling auto-notify for user %s: %v", userID, err)
+		b.respondWithError(s, i, "Failed to enable auto-notify")
+		return
+	}
+
+	tracked, err := b.autoNotifier.SyncUser(ctx, userID)
+	if err != nil {
+		log.Printf("Error syncing auto-notify for user %s: %v", userID, err)
+	}
+
+	description := fmt.Sprintf("Every airing anime you're planning or watching on your watchlist now gets episode alerts. %d anime are airing right now.", tracked)
+	description += "\nAnime that haven't started yet are picked up as soon as their first episode is scheduled."
+	switch {
+	case dm:
+		description += "\nAlerts will be sent to your DMs."
+	case channelID != i.ChannelID:
+		description += fmt.Sprintf("\nAlerts will be posted in <#%s>.", channelID)
+	default:
+		description += deliveryNote(i, b.guildSettings(i.GuildID), false)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Watchlist Auto-Notify Enabled",
+		Description: description,
+		Color:       0x00FF00,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Stop with /anime notify action:auto-off",
+		},
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+	if err != nil {
+		log.Printf("Failed to edit interaction response: %v", err)
+	}
+}
+
+// handleNotifyAutoOffCommand opts the user out of watchlist auto-notify and removes the alerts it added
+func (b *Bot) handleNotifyAutoOffCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx := context.Background()
+	userID := interactionUser(i).ID
+
+	msg := "Watchlist auto-notify is off. Alerts you added by hand are kept."
+	if err := b.autoNotifier.Disable(ctx, userID); err != nil {
+		log.Printf("Error disabling auto-notify for user %s: %v", userID, err)
+		msg = "Failed to disable auto-notify"
+	} else if _, err := b.autoNotifier.SyncUser(ctx, userID); err != nil {
+		log.Printf("Error removing auto-notify alerts for user %s: %v", userID, err)
+	}
+
+	if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg}); err != nil {
+		log.Printf("Failed to edit interaction response: %v", err)
+	}
+}
+
+// refreshAutoNotify re-checks a user's watchlist auto-notify in the background after their watchlist changed
+func (b *Bot) refreshAutoNotify(userID string) {
+	go func() {
+		if _, err := b.autoNotifier.SyncUser(context.Background(), userID); err != nil {
+			log.Printf("Error syncing auto-notify for user %s: %v", userID, err)
+		}
+	}()
+}
+
+// deliveryNote tells the user where their alerts will show up when that is not the current channel
+func deliveryNote(i *discordgo.InteractionCreate, guild *settings.Settings, dm bool) string {
+	if dm {
+		return "\nAlerts will be sent to your DMs."
+	}
+	if guild.NotificationChannelID != "" && guild.NotificationChannelID != i.ChannelID {
+		return fmt.Sprintf("\nAlerts for this server are posted in <#%s>.", guild.NotificationChannelID)
+	}
+	return ""
+}
@@ -1,12 +1,11 @@
 package bot
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
-	"discord-anime-bot/internal/services/anilist"
 	"discord-anime-bot/internal/utils"
 
 	"github.com/bwmarrin/discordgo"
@@ -34,24 +33,33 @@ func (b *Bot) handleReleaseCommand(s *discordgo.Session, i *discordgo.Interactio
 		}
 	}
 
-	releasingAnime, err := anilist.GetReleasingAnime(page, perPage)
+	b.sendPaginated(s, i, &paginationState{
+		Kind:    paginationRelease,
+		Page:    max(page, 1),
+		PerPage: min(max(perPage, 1), 50),
+	})
+}
+
+// renderReleasePage renders one page of currently releasing anime
+func (b *Bot) renderReleasePage(state *paginationState) (*renderedPage, error) {
+	releasingAnime, err := b.anilist.GetReleasingAnime(context.Background(), state.Page, state.PerPage)
 	if err != nil {
-		log.Printf("Error getting releasing anime: %v", err)
-		b.respondWithError(s, i, "An error occurred while fetching releasing anime.")
-		return
+		return nil, err
 	}
 
+	pageInfo := releasingAnime.Data.Page.PageInfo
 	if len(releasingAnime.Data.Page.Media) == 0 {
-		b.respondWithError(s, i, "No releasing anime found.")
-		return
+		return &renderedPage{
+			Content:  "No releasing anime found.",
+			Embeds:   []*discordgo.MessageEmbed{},
+			LastPage: pageInfo.LastPage,
+		}, nil
 	}
 
+	guild := b.guildSettings(state.GuildID)
 	var animeList []string
 	for _, anime := range releasingAnime.Data.Page.Media {
-		title := anime.Title.Romaji
-		if anime.Title.English != nil && *anime.Title.English != "" {
-			title = *anime.Title.English
-		}
+		title := guild.Title(anime.Title)
 
 		var nextEpisodeInfo string
 		if anime.NextAiringEpisode != nil {
@@ -65,7 +73,6 @@ func (b *Bot) handleReleaseCommand(s *discordgo.Session, i *discordgo.Interactio
 		animeList = append(animeList, fmt.Sprintf("**%s** (ID: %d)%s", title, anime.ID, nextEpisodeInfo))
 	}
 
-	pageInfo := releasingAnime.Data.Page.PageInfo
 	embed := &discordgo.MessageEmbed{
 		Title:       "Currently Releasing Anime",
 		Description: strings.Join(animeList, "\n"),
@@ -75,10 +82,8 @@ func (b *Bot) handleReleaseCommand(s *discordgo.Session, i *discordgo.Interactio
 		},
 	}
 
-	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Embeds: &[]*discordgo.MessageEmbed{embed},
-	})
-	if err != nil {
-		log.Printf("Failed to edit interaction response: %v", err)
-	}
+	return &renderedPage{
+		Embeds:   []*discordgo.MessageEmbed{embed},
+		LastPage: pageInfo.LastPage,
+	}, nil
 }
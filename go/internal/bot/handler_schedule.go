@@ -0,0 +1,173 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"discord-anime-bot/internal/services/digest"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// scheduleEpisodesPerPage is how many episodes are listed per schedule page
+const scheduleEpisodesPerPage = 25
+
+// weekdays maps the day option of /anime schedule to weekdays
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// handleScheduleCommand handles the /anime schedule command
+func (b *Bot) handleScheduleCommand(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var day, timezone string
+	var hours int
+
+	for _, option := range options {
+		switch option.Name {
+		case "day":
+			day = option.StringValue()
+		case "hours":
+			hours = int(option.IntValue())
+		case "timezone":
+			timezone = strings.TrimSpace(option.StringValue())
+		}
+	}
+
+	if day != "" && hours > 0 {
+		b.respondWithError(s, i, "Please choose either a day or a number of hours, not both.")
+		return
+	}
+
+	// Days are grouped in the requested time zone, falling back to the server's
+	location := b.guildSettings(i.GuildID).Location()
+	if timezone != "" {
+		loaded, err := time.LoadLocation(timezone)
+		if err != nil || strings.EqualFold(timezone, "local") {
+			b.respondWithError(s, i, fmt.Sprintf("Unknown time zone %q. Use an IANA name such as Europe/Berlin, America/New_York or Asia/Tokyo.", timezone))
+			return
+		}
+		location = loaded
+	}
+
+	// Truncating to the minute keeps the window stable while paging, so AniList responses stay cached
+	now := time.Now().Truncate(time.Minute)
+	from, to, label := scheduleWindow(now.In(location), day, hours)
+
+	b.sendPaginated(s, i, &paginationState{
+		Kind:  paginationSchedule,
+		Query: label,
+		Options: map[string]string{
+			"from":     strconv.FormatInt(from.Unix(), 10),
+			"to":       strconv.FormatInt(to.Unix(), 10),
+			"timezone": location.String(),
+		},
+		Page:    1,
+		PerPage: scheduleEpisodesPerPage,
+	})
+}
+
+// scheduleWindow returns the time window and a description of it for a day or hours option
+// Without either option the window is the next 24 hours
+func scheduleWindow(now time.Time, day string, hours int) (from, to time.Time, label string) {
+	if hours > 0 {
+		return now, now.Add(time.Duration(hours) * time.Hour), fmt.Sprintf("next %d hours", hours)
+	}
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch day {
+	case "today":
+		return midnight, midnight.AddDate(0, 0, 1), "today"
+	case "tomorrow":
+		return midnight.AddDate(0, 0, 1), midnight.AddDate(0, 0, 2), "tomorrow"
+	case "":
+		return now, now.Add(24 * time.Hour), "next 24 hours"
+	}
+
+	// The next occurrence of the weekday, which is today if it already is that day
+	offset := (int(weekdays[day]) - int(now.Weekday()) + 7) % 7
+	start := midnight.AddDate(0, 0, offset)
+	return start, start.AddDate(0, 0, 1), start.Format("Monday, January 2")
+}
+
+// renderSchedulePage renders one page of an airing schedule, grouped by day
+func (b *Bot) renderSchedulePage(state *paginationState) (*renderedPage, error) {
+	from, err := strconv.ParseInt(state.Options["from"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule start %q: %w", state.Options["from"], err)
+	}
+	to, err := strconv.ParseInt(state.Options["to"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule end %q: %w", state.Options["to"], err)
+	}
+	location, err := time.LoadLocation(state.Options["timezone"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule time zone %q: %w", state.Options["timezone"], err)
+	}
+
+	schedules, err := b.anilist.GetAiringSchedule(context.Background(), time.Unix(from, 0), time.Unix(to, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(schedules) == 0 {
+		return &renderedPage{
+			Content:  fmt.Sprintf("No episodes air %s.", scheduleLabelPhrase(state.Query)),
+			Embeds:   []*discordgo.MessageEmbed{},
+			LastPage: 1,
+		}, nil
+	}
+
+	lastPage := (len(schedules) + state.PerPage - 1) / state.PerPage
+	// The schedule can shrink between clicks, so store the clamped page for the next one
+	page := min(state.Page, lastPage)
+	state.Page = page
+	start := (page - 1) * state.PerPage
+	pageSchedules := schedules[start:min(start+state.PerPage, len(schedules))]
+
+	guild := b.guildSettings(state.GuildID)
+	var description strings.Builder
+	for _, day := range digest.GroupByDay(pageSchedules, location) {
+		if description.Len() > 0 {
+			description.WriteString("\n")
+		}
+		description.WriteString(fmt.Sprintf("**%s**\n", day.Date.Format("Monday, January 2")))
+		for _, schedule := range day.Schedules {
+			description.WriteString(digest.FormatScheduleLine(schedule, guild) + "\n")
+		}
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("📅 Airing %s", scheduleLabelPhrase(state.Query)),
+		Description: description.String(),
+		Color:       0x02A9FF,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Page %d/%d • %d episodes • Days in %s", page, lastPage, len(schedules), location),
+		},
+	}
+
+	return &renderedPage{
+		Embeds:   []*discordgo.MessageEmbed{embed},
+		LastPage: lastPage,
+	}, nil
+}
+
+// scheduleLabelPhrase turns a window label into a phrase that follows "airing"
+func scheduleLabelPhrase(label string) string {
+	switch {
+	case label == "today" || label == "tomorrow":
+		return label
+	case strings.HasPrefix(label, "next "):
+		return "in the " + label
+	default:
+		return "on " + label
+	}
+}
@@ -1,14 +1,15 @@
 package bot
 
 import (
+	"context"
 	"fmt"
-	"log"
-
-	"discord-anime-bot/internal/services/anilist"
 
 	"github.com/bwmarrin/discordgo"
 )
 
+// searchResultsPerPage is how many results are shown per page, one embed each
+const searchResultsPerPage = 5
+
 // handleSearchCommand handles the anime search subcommand
 func (b *Bot) handleSearchCommand(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
 	if len(options) == 0 {
@@ -22,30 +23,36 @@ func (b *Bot) handleSearchCommand(s *discordgo.Session, i *discordgo.Interaction
 		return
 	}
 
+	b.sendPaginated(s, i, &paginationState{
+		Kind:    paginationSearch,
+		Query:   query,
+		Page:    1,
+		PerPage: searchResultsPerPage,
+	})
+}
+
+// renderSearchPage renders one page of search results
+func (b *Bot) renderSearchPage(state *paginationState) (*renderedPage, error) {
 	// Search anime using AniList
-	searchResults, err := anilist.SearchAnime(query, 1, 5)
+	searchResults, err := b.anilist.SearchAnime(context.Background(), state.Query, state.Page, state.PerPage)
 	if err != nil {
-		log.Printf("Error searching anime: %v", err)
-		b.respondWithError(s, i, "An error occurred while searching for anime.")
-		return
+		return nil, err
 	}
 
+	pageInfo := searchResults.Data.Page.PageInfo
 	if len(searchResults.Data.Page.Media) == 0 {
-		b.respondWithError(s, i, fmt.Sprintf("No anime found for query: \"%s\"", query))
-		return
+		return &renderedPage{
+			Content:  fmt.Sprintf("No anime found for query: \"%s\"", state.Query),
+			Embeds:   []*discordgo.MessageEmbed{},
+			LastPage: pageInfo.LastPage,
+		}, nil
 	}
 
 	// Create embeds for search results
+	guild := b.guildSettings(state.GuildID)
 	var embeds []*discordgo.MessageEmbed
-	for i, anime := range searchResults.Data.Page.Media {
-		if i >= 3 { // Limit to 3 results
-			break
-		}
-
-		title := anime.Title.Romaji
-		if anime.Title.English != nil && *anime.Title.English != "" {
-			title = *anime.Title.English
-		}
+	for _, anime := range searchResults.Data.Page.Media {
+		title := guild.Title(anime.Title)
 
 		embed := &discordgo.MessageEmbed{
 			Title: fmt.Sprintf("📺 %s", title),
@@ -65,14 +72,12 @@ func (b *Bot) handleSearchCommand(s *discordgo.Session, i *discordgo.Interaction
 		embeds = append(embeds, embed)
 	}
 
-	responseText := fmt.Sprintf("🔍 **Search Results for:** \"%s\"\n\nFound %d results (showing top %d):",
-		query, searchResults.Data.Page.PageInfo.Total, len(embeds))
+	content := fmt.Sprintf("🔍 **Search Results for:** \"%s\"\n\nFound %d results (page %d of %d):",
+		state.Query, pageInfo.Total, pageInfo.CurrentPage, max(pageInfo.LastPage, 1))
 
-	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Content: &responseText,
-		Embeds:  &embeds,
-	})
-	if err != nil {
-		log.Printf("Failed to edit interaction response: %v", err)
-	}
+	return &renderedPage{
+		Content:  content,
+		Embeds:   embeds,
+		LastPage: pageInfo.LastPage,
+	}, nil
 }
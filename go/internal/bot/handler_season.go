@@ -1,16 +1,21 @@
 package bot
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
-	"discord-anime-bot/internal/services/anilist"
+	"discord-anime-bot/internal/services/settings"
 	"discord-anime-bot/internal/types"
 
 	"github.com/bwmarrin/discordgo"
 )
 
+// seasonAnimePerPage is how many seasonal anime are listed per page
+const seasonAnimePerPage = 20
+
 // handleSeasonCommand handles the /anime season command
 func (b *Bot) handleSeasonCommand(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
 	// Get season parameter
@@ -42,105 +47,69 @@ func (b *Bot) handleSeasonCommand(s *discordgo.Session, i *discordgo.Interaction
 		year = int(options[1].IntValue())
 	}
 
+	b.sendPaginated(s, i, &paginationState{
+		Kind:    paginationSeason,
+		Query:   strings.ToLower(season),
+		Options: map[string]string{"year": strconv.Itoa(year)},
+		Page:    1,
+		PerPage: seasonAnimePerPage,
+	})
+}
+
+// renderSeasonPage renders one page of seasonal anime
+func (b *Bot) renderSeasonPage(state *paginationState) (*renderedPage, error) {
+	season := state.Query
+	year, err := strconv.Atoi(state.Options["year"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid season year %q: %w", state.Options["year"], err)
+	}
+
 	// Fetch seasonal anime
-	seasonAnime, err := anilist.GetSeasonAnime(season, year, 1, 50)
+	seasonAnime, err := b.anilist.GetSeasonAnime(context.Background(), season, year, state.Page, state.PerPage)
 	if err != nil {
-		b.respondWithError(s, i, "An error occurred while fetching seasonal anime.")
-		return
+		return nil, err
 	}
 
+	pageInfo := seasonAnime.Data.Page.PageInfo
 	if len(seasonAnime.Data.Page.Media) == 0 {
-		b.respondWithError(s, i, fmt.Sprintf("No anime found for %s %d.", season, year))
-		return
+		return &renderedPage{
+			Content:  fmt.Sprintf("No anime found for %s %d.", season, year),
+			Embeds:   []*discordgo.MessageEmbed{},
+			LastPage: pageInfo.LastPage,
+		}, nil
 	}
 
-	// Create embeds for the seasonal anime
-	embeds := b.createSeasonEmbeds(seasonAnime.Data.Page.Media, season, year)
-
-	// Discord allows up to 10 embeds per message
-	if len(embeds) <= 10 {
-		embedsSlice := embeds
-		_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Embeds: &embedsSlice,
-		})
-	} else {
-		// Send first 10 embeds initially
-		firstEmbeds := embeds[:10]
-		_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Embeds: &firstEmbeds,
-		})
-		if err == nil {
-			// Send remaining embeds as follow-up messages
-			for j := 10; j < len(embeds); j += 10 {
-				end := j + 10
-				if end > len(embeds) {
-					end = len(embeds)
-				}
-				embedSlice := embeds[j:end]
-				_, err = s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
-					Embeds: embedSlice,
-				})
-				if err != nil {
-					break
-				}
-			}
-		}
-	}
+	embed := createSeasonEmbed(seasonAnime.Data.Page.Media, season, year, pageInfo, b.guildSettings(state.GuildID))
 
-	if err != nil {
-		b.respondWithError(s, i, "Failed to send seasonal anime information.")
-	}
+	return &renderedPage{
+		Embeds:   []*discordgo.MessageEmbed{embed},
+		LastPage: pageInfo.LastPage,
+	}, nil
 }
 
-// createSeasonEmbeds creates Discord embeds for seasonal anime
-func (b *Bot) createSeasonEmbeds(media []types.SeasonAnime, season string, year int) []*discordgo.MessageEmbed {
-	const animePerEmbed = 20
-	totalEmbeds := (len(media) + animePerEmbed - 1) / animePerEmbed
-	embeds := make([]*discordgo.MessageEmbed, 0, totalEmbeds)
-
-	for i := 0; i < totalEmbeds; i++ {
-		startIndex := i * animePerEmbed
-		endIndex := startIndex + animePerEmbed
-		if endIndex > len(media) {
-			endIndex = len(media)
-		}
-
-		animeSlice := media[startIndex:endIndex]
-		var description strings.Builder
+// createSeasonEmbed creates the Discord embed for one page of seasonal anime
+func createSeasonEmbed(media []types.SeasonAnime, season string, year int, pageInfo types.PageInfo, guild *settings.Settings) *discordgo.MessageEmbed {
+	startIndex := (pageInfo.CurrentPage - 1) * seasonAnimePerPage
+	var description strings.Builder
 
-		for j, anime := range animeSlice {
-			title := anime.Title.Romaji
-			if anime.Title.English != nil && *anime.Title.English != "" {
-				title = *anime.Title.English
-			}
+	for j, anime := range media {
+		title := guild.Title(anime.Title)
 
-			statusEmoji := getStatusEmoji(anime.Status)
-			description.WriteString(fmt.Sprintf("%d. **%s** %s (ID: %d)\n", startIndex+j+1, title, statusEmoji, anime.ID))
-		}
+		statusEmoji := getStatusEmoji(anime.Status)
+		description.WriteString(fmt.Sprintf("%d. **%s** %s (ID: %d)\n", startIndex+j+1, title, statusEmoji, anime.ID))
+	}
 
 	// Capitalize first letter of season
 	capitalizedSeason := strings.ToUpper(season[:1]) + strings.ToLower(season[1:])
-	embedTitle := fmt.Sprintf("%s %d Anime", capitalizedSeason, year)
-		if totalEmbeds > 1 {
-			embedTitle += fmt.Sprintf(" (Part %d/%d)", i+1, totalEmbeds)
-		}
 
-		embed := &discordgo.MessageEmbed{
-			Title:       embedTitle,
-			Description: description.String(),
-			Color:       0x02A9FF,
-		}
-
-		if i == 0 {
-			embed.Footer = &discordgo.MessageEmbedFooter{
-				Text: fmt.Sprintf("Showing all %d anime from %s %d", len(media), season, year),
-			}
-		}
-
-		embeds = append(embeds, embed)
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s %d Anime", capitalizedSeason, year),
+		Description: description.String(),
+		Color:       0x02A9FF,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Page %d/%d • %d anime from %s %d", pageInfo.CurrentPage, pageInfo.LastPage, pageInfo.Total, season, year),
+		},
 	}
-
-	return embeds
 }
 
 // getStatusEmoji returns an emoji for the anime status
@@ -1,19 +1,50 @@
 package bot
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"discord-anime-bot/internal/services/anilist"
+	"discord-anime-bot/internal/types"
+	"discord-anime-bot/internal/utils"
 
 	"github.com/bwmarrin/discordgo"
 )
 
+// watchlistEntriesPerPage is how many anime are listed per watchlist page
+const watchlistEntriesPerPage = 10
+
+// Watchlist sort orders
+const (
+	watchlistSortAiring  = "airing"
+	watchlistSortTitle   = "title"
+	watchlistSortAdded   = "added"
+	watchlistSortUpdated = "updated"
+)
+
+// watchlistSortNames describes each sort order in the list footer
+var watchlistSortNames = map[string]string{
+	watchlistSortAiring:  "next airing",
+	watchlistSortTitle:   "title",
+	watchlistSortAdded:   "recently added",
+	watchlistSortUpdated: "recently updated",
+}
+
 // handleWatchlistCommand handles the anime watchlist command
 func (b *Bot) handleWatchlistCommand(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
 	var action string
-	var animeID int
+	var animeQuery string
+	var status string
+	sortBy := watchlistSortAiring
+	progress := -1
+	score := -1.0
 
 	// Parse options
 	for _, option := range options {
@@ -21,18 +52,35 @@ func (b *Bot) handleWatchlistCommand(s *discordgo.Session, i *discordgo.Interact
 		case "action":
 			action = option.StringValue()
 		case "id":
-			animeID = int(option.IntValue())
+			animeQuery = option.StringValue()
+		case "sort":
+			sortBy = option.StringValue()
+		case "status":
+			status = option.StringValue()
+		case "progress":
+			progress = int(option.IntValue())
+		case "score":
+			score = option.FloatValue()
 		}
 	}
 
 	// If no action is specified, show the list
 	if action == "" {
-		b.handleWatchlistListCommand(s, i)
+		b.handleWatchlistListCommand(s, i, sortBy, status)
+		return
+	}
+
+	switch action {
+	case "import":
+		b.handleWatchlistImportCommand(s, i)
+		return
+	case "export":
+		b.handleWatchlistExportCommand(s, i)
 		return
 	}
 
-	// Validate that ID is provided for actions that require it
-	if (action == "add" || action == "remove") && animeID == 0 {
+	// Every other action works on a single anime
+	if animeQuery == "" {
 		msg := "Please provide an anime ID for this action."
 		if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg}); err != nil {
 			log.Printf("Failed to edit interaction response: %v", err)
@@ -40,33 +88,60 @@ func (b *Bot) handleWatchlistCommand(s *discordgo.Session, i *discordgo.Interact
 		return
 	}
 
+	animeID, err := b.resolveAnimeID(context.Background(), animeQuery)
+	if err != nil {
+		b.respondWithError(s, i, resolveAnimeIDMessage(err, animeQuery))
+		return
+	}
+
 	switch action {
 	case "add":
-		b.handleWatchlistAddCommand(s, i, animeID)
+		if status == "" {
+			status = types.WatchlistPlanning
+		}
+		b.handleWatchlistAddCommand(s, i, animeID, status)
 	case "remove":
 		b.handleWatchlistRemoveCommand(s, i, animeID)
+	case "watched":
+		b.handleWatchlistWatchedCommand(s, i, animeID)
+	case "progress":
+		if progress < 0 {
+			b.respondWithError(s, i, "Please provide the number of episodes watched with the `progress` option.")
+			return
+		}
+		b.handleWatchlistProgressCommand(s, i, animeID, progress)
+	case "status":
+		if status == "" {
+			b.respondWithError(s, i, "Please provide the new status with the `status` option.")
+			return
+		}
+		b.handleWatchlistStatusCommand(s, i, animeID, status)
+	case "score":
+		if score < 0 {
+			b.respondWithError(s, i, "Please provide a score with the `score` option.")
+			return
+		}
+		b.handleWatchlistScoreCommand(s, i, animeID, score)
 	default:
 		b.respondWithError(s, i, "Unknown watchlist action")
 	}
 }
 
-func (b *Bot) handleWatchlistAddCommand(s *discordgo.Session, i *discordgo.InteractionCreate, animeID int) {
-	userID := i.Member.User.ID
-	msg, err := anilist.AddToWatchlist(userID, animeID)
+func (b *Bot) handleWatchlistAddCommand(s *discordgo.Session, i *discordgo.InteractionCreate, animeID int, status string) {
+	userID := interactionUser(i).ID
+	msg, err := b.anilist.AddToWatchlist(userID, animeID, status)
 	if err == nil && msg == "Anime added to your watchlist." {
 		// Fetch anime name for confirmation
-		anime, err := anilist.GetAnimeByID(animeID)
+		anime, err := b.anilist.GetAnimeByID(context.Background(), animeID)
 		var title string
 		if err == nil && anime != nil {
-			if anime.Title.English != nil && *anime.Title.English != "" {
-				title = *anime.Title.English
-			} else {
-				title = anime.Title.Romaji
-			}
+			title = b.guildSettings(i.GuildID).Title(anime.Title)
 		} else {
 			title = fmt.Sprintf("Anime ID %d", animeID)
 		}
-		msg = fmt.Sprintf("Added **%s** (ID: %d) to your watchlist.", title, animeID)
+		msg = fmt.Sprintf("Added **%s** (ID: %d) to your watchlist as %s.", title, animeID, strings.ToLower(types.WatchlistStatusNames[status]))
+		msg += b.syncWatchlistEntry(userID, animeID)
+		b.refreshAutoNotify(userID)
 	} else if err != nil {
 		msg = "Failed to add to watchlist"
 	}
@@ -75,51 +150,359 @@ func (b *Bot) handleWatchlistAddCommand(s *discordgo.Session, i *discordgo.Inter
 	}
 }
 
-func (b *Bot) handleWatchlistListCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	userID := i.Member.User.ID
-	ids, err := anilist.GetUserWatchlist(userID)
+// handleWatchlistWatchedCommand marks the next episode of an anime as watched
+func (b *Bot) handleWatchlistWatchedCommand(s *discordgo.Session, i *discordgo.InteractionCreate, animeID int) {
+	anime, err := b.anilist.GetAnimeByID(context.Background(), animeID)
 	if err != nil {
-		msg := "Failed to fetch your watchlist"
-		if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg}); err != nil {
-			log.Printf("Failed to edit interaction response: %v", err)
+		log.Printf("Error getting anime details for %d: %v", animeID, err)
+		anime = nil
+	}
+
+	b.updateWatchlistEntry(s, i, animeID, anime, func(entry *types.WatchlistEntry) {
+		setWatchlistProgress(entry, anime, entry.Progress+1)
+	})
+}
+
+// handleWatchlistProgressCommand sets how many episodes of an anime have been watched
+func (b *Bot) handleWatchlistProgressCommand(s *discordgo.Session, i *discordgo.InteractionCreate, animeID int, progress int) {
+	anime, err := b.anilist.GetAnimeByID(context.Background(), animeID)
+	if err != nil {
+		log.Printf("Error getting anime details for %d: %v", animeID, err)
+		anime = nil
+	}
+
+	b.updateWatchlistEntry(s, i, animeID, anime, func(entry *types.WatchlistEntry) {
+		setWatchlistProgress(entry, anime, progress)
+	})
+}
+
+// handleWatchlistStatusCommand changes the status of a watchlist entry
+func (b *Bot) handleWatchlistStatusCommand(s *discordgo.Session, i *discordgo.InteractionCreate, animeID int, status string) {
+	anime, err := b.anilist.GetAnimeByID(context.Background(), animeID)
+	if err != nil {
+		log.Printf("Error getting anime details for %d: %v", animeID, err)
+		anime = nil
+	}
+
+	b.updateWatchlistEntry(s, i, animeID, anime, func(entry *types.WatchlistEntry) {
+		entry.Status = status
+		// Completing a show with a known length fills in the remaining episodes
+		if status == types.WatchlistCompleted && anime != nil && anime.Episodes != nil {
+			entry.Progress = *anime.Episodes
 		}
+	})
+}
+
+// handleWatchlistScoreCommand sets the score of a watchlist entry; 0 clears it
+func (b *Bot) handleWatchlistScoreCommand(s *discordgo.Session, i *discordgo.InteractionCreate, animeID int, score float64) {
+	anime, err := b.anilist.GetAnimeByID(context.Background(), animeID)
+	if err != nil {
+		log.Printf("Error getting anime details for %d: %v", animeID, err)
+		anime = nil
+	}
+
+	b.updateWatchlistEntry(s, i, animeID, anime, func(entry *types.WatchlistEntry) {
+		entry.Score = score
+	})
+}
+
+// handleWatchlistImportCommand copies the user's AniList list into their watchlist
+func (b *Bot) handleWatchlistImportCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	result, err := b.anilist.ImportAniListList(context.Background(), interactionUser(i).ID)
+	if err != nil {
+		b.respondWithSyncError(s, i, "import", err)
 		return
 	}
-	if len(ids) == 0 {
-		msg := "Your watchlist is empty."
-		if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg}); err != nil {
-			log.Printf("Failed to edit interaction response: %v", err)
-		}
+
+	b.refreshAutoNotify(interactionUser(i).ID)
+
+	msg := fmt.Sprintf("Imported your AniList list: %d added, %d updated.", result.Added, result.Updated)
+	if result.Failed > 0 {
+		msg += fmt.Sprintf(" %d entries could not be imported.", result.Failed)
+	}
+	if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg}); err != nil {
+		log.Printf("Failed to edit interaction response: %v", err)
+	}
+}
+
+// handleWatchlistExportCommand pushes the user's watchlist to their AniList list
+func (b *Bot) handleWatchlistExportCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	result, err := b.anilist.ExportWatchlist(context.Background(), interactionUser(i).ID)
+	if err != nil {
+		b.respondWithSyncError(s, i, "export", err)
 		return
 	}
-	var sb strings.Builder
-	sb.WriteString("Your Anime Watchlist:\n")
-	for _, id := range ids {
-		sb.WriteString(fmt.Sprintf("• AniList ID: %d\n", id))
+
+	msg := fmt.Sprintf("Exported %d watchlist entries to AniList.", result.Updated)
+	if result.Failed > 0 {
+		msg += fmt.Sprintf(" %d entries could not be exported.", result.Failed)
 	}
-	msg := sb.String()
 	if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg}); err != nil {
 		log.Printf("Failed to edit interaction response: %v", err)
 	}
 }
 
+// respondWithSyncError reports a failed import or export, pointing unlinked users to /anime link
+func (b *Bot) respondWithSyncError(s *discordgo.Session, i *discordgo.InteractionCreate, operation string, err error) {
+	if errors.Is(err, anilist.ErrNotLinked) {
+		b.respondWithError(s, i, "Link your AniList account with `/anime link` first.")
+		return
+	}
+	log.Printf("Error during AniList %s: %v", operation, err)
+	b.respondWithError(s, i, fmt.Sprintf("Failed to %s your AniList list. Please try again later.", operation))
+}
+
+// setWatchlistProgress sets the episodes watched, capped at the episode count when known,
+// and moves the entry to watching or completed to match
+func setWatchlistProgress(entry *types.WatchlistEntry, anime *types.AnimeDetails, progress int) {
+	if anime != nil && anime.Episodes != nil {
+		progress = min(progress, *anime.Episodes)
+	}
+	entry.Progress = progress
+
+	switch {
+	case anime != nil && anime.Status == "FINISHED" && anime.Episodes != nil && progress >= *anime.Episodes:
+		entry.Status = types.WatchlistCompleted
+	case progress > 0 && entry.Status != types.WatchlistWatching:
+		entry.Status = types.WatchlistWatching
+	}
+}
+
+// updateWatchlistEntry applies an update to a watchlist entry and replies with the updated entry
+func (b *Bot) updateWatchlistEntry(s *discordgo.Session, i *discordgo.InteractionCreate, animeID int, anime *types.AnimeDetails, update func(entry *types.WatchlistEntry)) {
+	entry, err := b.anilist.UpdateWatchlistEntry(interactionUser(i).ID, animeID, update)
+	if errors.Is(err, anilist.ErrNotInWatchlist) {
+		b.respondWithError(s, i, "That anime is not in your watchlist. Add it first with `/anime watchlist action:add`.")
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating watchlist entry for anime %d: %v", animeID, err)
+		b.respondWithError(s, i, "Failed to update watchlist")
+		return
+	}
+
+	title := fmt.Sprintf("Anime ID %d", animeID)
+	if anime != nil {
+		title = b.guildSettings(i.GuildID).Title(anime.Title)
+	}
+
+	msg := fmt.Sprintf("Updated **%s** (ID: %d): %s", title, animeID, formatWatchlistProgress(entry, anime))
+	msg += b.syncWatchlistEntry(interactionUser(i).ID, animeID)
+	b.refreshAutoNotify(interactionUser(i).ID)
+	if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &msg}); err != nil {
+		log.Printf("Failed to edit interaction response: %v", err)
+	}
+}
+
+// handleWatchlistListCommand shows the user's watchlist as a paginated embed, optionally limited to one status
+func (b *Bot) handleWatchlistListCommand(s *discordgo.Session, i *discordgo.InteractionCreate, sortBy string, status string) {
+	b.sendPaginated(s, i, &paginationState{
+		Kind:    paginationWatchlist,
+		Options: map[string]string{"sort": sortBy, "status": status},
+		Page:    1,
+		PerPage: watchlistEntriesPerPage,
+	})
+}
+
+// watchlistEntry is a watchlist item joined with its AniList details
+type watchlistEntry struct {
+	types.WatchlistEntry
+	anime *types.AnimeDetails // nil when AniList no longer knows the ID
+	title string
+}
+
+// renderWatchlistPage renders one page of the watchlist of the user who ran the command
+func (b *Bot) renderWatchlistPage(state *paginationState) (*renderedPage, error) {
+	ctx := context.Background()
+
+	stored, err := b.anilist.GetWatchlistEntries(state.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	statusFilter := state.Options["status"]
+	if statusFilter != "" {
+		stored = slices.DeleteFunc(stored, func(entry types.WatchlistEntry) bool {
+			return entry.Status != statusFilter
+		})
+	}
+	if len(stored) == 0 {
+		content := "Your watchlist is empty. Add anime with `/anime watchlist action:add`."
+		if statusFilter != "" {
+			content = fmt.Sprintf("You have no anime marked as %s.", strings.ToLower(types.WatchlistStatusNames[statusFilter]))
+		}
+		return &renderedPage{
+			Content:  content,
+			Embeds:   []*discordgo.MessageEmbed{},
+			LastPage: 1,
+		}, nil
+	}
+
+	animeIDs := make([]int, 0, len(stored))
+	for _, entry := range stored {
+		animeIDs = append(animeIDs, entry.AnimeID)
+	}
+	details, err := b.anilist.GetAnimeByIDs(ctx, animeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	guild := b.guildSettings(state.GuildID)
+	entries := make([]watchlistEntry, 0, len(stored))
+	for _, item := range stored {
+		entry := watchlistEntry{WatchlistEntry: item, anime: details[item.AnimeID]}
+		if entry.anime != nil {
+			entry.title = guild.Title(entry.anime.Title)
+		} else {
+			entry.title = fmt.Sprintf("Anime ID %d", item.AnimeID)
+		}
+		entries = append(entries, entry)
+	}
+	sortWatchlist(entries, state.Options["sort"])
+
+	lastPage := (len(entries) + state.PerPage - 1) / state.PerPage
+	// Removed entries can leave the saved page past the end
+	page := min(state.Page, lastPage)
+	state.Page = page
+	start := (page - 1) * state.PerPage
+
+	var lines []string
+	for _, entry := range entries[start:min(start+state.PerPage, len(entries))] {
+		lines = append(lines, formatWatchlistEntry(entry))
+	}
+
+	embedTitle := "📋 Your Watchlist"
+	if statusFilter != "" {
+		embedTitle += " · " + types.WatchlistStatusNames[statusFilter]
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       embedTitle,
+		Description: strings.Join(lines, "\n\n"),
+		Color:       0x02A9FF,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Page %d/%d • %d anime • Sorted by %s", page, lastPage, len(entries), watchlistSortNames[state.Options["sort"]]),
+		},
+	}
+
+	return &renderedPage{
+		Embeds:   []*discordgo.MessageEmbed{embed},
+		LastPage: lastPage,
+	}, nil
+}
+
+// sortWatchlist orders watchlist entries by the chosen sort, falling back to the title
+func sortWatchlist(entries []watchlistEntry, sortBy string) {
+	slices.SortStableFunc(entries, func(a, b watchlistEntry) int {
+		switch sortBy {
+		case watchlistSortAiring:
+			// Soonest next episode first, anime without a scheduled episode last
+			aNext, bNext := nextAiringAt(a.anime), nextAiringAt(b.anime)
+			if aNext != bNext {
+				if aNext == 0 || bNext == 0 {
+					return cmp.Compare(bNext, aNext)
+				}
+				return cmp.Compare(aNext, bNext)
+			}
+		case watchlistSortAdded:
+			// Newest first
+			if a.AddedAt != b.AddedAt {
+				return cmp.Compare(b.AddedAt, a.AddedAt)
+			}
+		case watchlistSortUpdated:
+			if a.UpdatedAt != b.UpdatedAt {
+				return cmp.Compare(b.UpdatedAt, a.UpdatedAt)
+			}
+		}
+		return cmp.Compare(strings.ToLower(a.title), strings.ToLower(b.title))
+	})
+}
+
+// nextAiringAt returns when an anime's next episode airs, or 0 when none is scheduled
+func nextAiringAt(anime *types.AnimeDetails) int {
+	if anime == nil || anime.NextAiringEpisode == nil {
+		return 0
+	}
+	return anime.NextAiringEpisode.AiringAt
+}
+
+// formatWatchlistEntry formats a watchlist entry with its format, airing status, the user's progress and the next episode
+func formatWatchlistEntry(entry watchlistEntry) string {
+	anime := entry.anime
+	if anime == nil {
+		return fmt.Sprintf("**%s**\n%s", entry.title, formatWatchlistProgress(&entry.WatchlistEntry, nil))
+	}
+
+	title := fmt.Sprintf("**%s**", entry.title)
+	if anime.SiteURL != "" {
+		title = fmt.Sprintf("**[%s](%s)**", entry.title, anime.SiteURL)
+	}
+
+	header := []string{title}
+	if anime.Format != "" {
+		header = append(header, anime.Format)
+	}
+	header = append(header, formatStatus(anime.Status))
+
+	aired := 0
+	switch {
+	case anime.NextAiringEpisode != nil:
+		aired = anime.NextAiringEpisode.Episode - 1
+	case anime.Status == "FINISHED" && anime.Episodes != nil:
+		aired = *anime.Episodes
+	}
+
+	details := formatWatchlistProgress(&entry.WatchlistEntry, anime) + fmt.Sprintf(" · %d aired", aired)
+	if anime.NextAiringEpisode != nil {
+		airingTime := time.Unix(int64(anime.NextAiringEpisode.AiringAt), 0)
+		details += fmt.Sprintf(" · Next: Ep %d %s", anime.NextAiringEpisode.Episode, utils.FormatRelativeTimestamp(airingTime))
+	}
+
+	return strings.Join(header, " · ") + "\n" + details
+}
+
+// formatWatchlistProgress formats the user's status, episodes watched and score, e.g. "Watching · 3/12 watched · ★ 8.5"
+func formatWatchlistProgress(entry *types.WatchlistEntry, anime *types.AnimeDetails) string {
+	total := "?"
+	if anime != nil && anime.Episodes != nil {
+		total = strconv.Itoa(*anime.Episodes)
+	}
+
+	status, ok := types.WatchlistStatusNames[entry.Status]
+	if !ok {
+		status = "Planning"
+	}
+
+	text := fmt.Sprintf("%s · %d/%s watched", status, entry.Progress, total)
+	if entry.Score > 0 {
+		text += " · ★ " + strconv.FormatFloat(entry.Score, 'f', -1, 64)
+	}
+	return text
+}
+
+// formatStatus turns an AniList status such as NOT_YET_RELEASED into "Not yet released"
+func formatStatus(status string) string {
+	if status == "" {
+		return "Unknown"
+	}
+	words := strings.ToLower(strings.ReplaceAll(status, "_", " "))
+	return strings.ToUpper(words[:1]) + words[1:]
+}
+
 func (b *Bot) handleWatchlistRemoveCommand(s *discordgo.Session, i *discordgo.InteractionCreate, animeID int) {
-	userID := i.Member.User.ID
-	msg, err := anilist.RemoveFromWatchlist(userID, animeID)
+	userID := interactionUser(i).ID
+	msg, err := b.anilist.RemoveFromWatchlist(userID, animeID)
 	if err == nil && msg == "Anime removed from your watchlist." {
 		// Fetch anime name for confirmation
-		anime, err := anilist.GetAnimeByID(animeID)
+		anime, err := b.anilist.GetAnimeByID(context.Background(), animeID)
 		var title string
 		if err == nil && anime != nil {
-			if anime.Title.English != nil && *anime.Title.English != "" {
-				title = *anime.Title.English
-			} else {
-				title = anime.Title.Romaji
-			}
+			title = b.guildSettings(i.GuildID).Title(anime.Title)
 		} else {
 			title = fmt.Sprintf("Anime ID %d", animeID)
 		}
 		msg = fmt.Sprintf("Removed **%s** (ID: %d) from your watchlist.", title, animeID)
+		b.refreshAutoNotify(userID)
 	} else if err != nil {
 		msg = "Failed to remove from watchlist"
 	}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log"
+	"net/http"
+	"time"
+)
+
+// newMetricsServer builds the internal HTTP server that serves the bot's expvar metrics at /debug/vars
+// It is kept apart from the public OAuth callback server so metrics are never exposed with it
+func newMetricsServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /debug/vars", expvar.Handler())
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// startMetricsServer serves metrics in the background until stopMetricsServer is called
+func (b *Bot) startMetricsServer() {
+	if b.metricsServer == nil {
+		return
+	}
+
+	go func() {
+		log.Printf("Serving metrics on %s/debug/vars", b.metricsServer.Addr)
+		if err := b.metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Metrics server stopped: %v", err)
+		}
+	}()
+}
+
+// stopMetricsServer gracefully shuts down the metrics server
+func (b *Bot) stopMetricsServer() {
+	if b.metricsServer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := b.metricsServer.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down metrics server: %v", err)
+	}
+}
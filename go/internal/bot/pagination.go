@@ -0,0 +1,205 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	paginationKeyPrefix      = "pagination:"
+	paginationCustomIDPrefix = "paginate"
+	// paginationTTL is how long Previous/Next buttons keep working after the last page change
+	paginationTTL = 15 * time.Minute
+)
+
+// Paginated result kinds, each rendered by its own page renderer
+const (
+	paginationSearch    = "search"
+	paginationRelease   = "release"
+	paginationSeason    = "season"
+	paginationSchedule  = "schedule"
+	paginationWatchlist = "watchlist"
+)
+
+// paginationState is the stored state behind a paginated message
+type paginationState struct {
+	ID      string            `json:"id"`
+	Kind    string            `json:"kind"`
+	Query   string            `json:"query"`
+	Options map[string]string `json:"options,omitempty"` // kind-specific extras, e.g. the season year
+	Page    int               `json:"page"`
+	PerPage int               `json:"perPage"`
+	UserID  string            `json:"userId"`
+	GuildID string            `json:"guildId,omitempty"` // guild whose settings the pages are rendered with
+}
+
+// renderedPage is a single rendered page of a paginated result
+type renderedPage struct {
+	Content  string
+	Embeds   []*discordgo.MessageEmbed
+	LastPage int
+}
+
+// renderPage renders the current page of a paginated result
+func (b *Bot) renderPage(state *paginationState) (*renderedPage, error) {
+	switch state.Kind {
+	case paginationSearch:
+		return b.renderSearchPage(state)
+	case paginationRelease:
+		return b.renderReleasePage(state)
+	case paginationSeason:
+		return b.renderSeasonPage(state)
+	case paginationSchedule:
+		return b.renderSchedulePage(state)
+	case paginationWatchlist:
+		return b.renderWatchlistPage(state)
+	default:
+		return nil, fmt.Errorf("unknown pagination kind %q", state.Kind)
+	}
+}
+
+// sendPaginated renders the first page of a result and edits the deferred interaction response with it
+func (b *Bot) sendPaginated(s *discordgo.Session, i *discordgo.InteractionCreate, state *paginationState) {
+	state.ID = i.ID
+	state.UserID = interactionUser(i).ID
+	state.GuildID = i.GuildID
+
+	page, err := b.renderPage(state)
+	if err != nil {
+		log.Printf("Error rendering %s page: %v", state.Kind, err)
+		b.respondWithError(s, i, "An error occurred while fetching results.")
+		return
+	}
+
+	components := paginationComponents(state, page)
+	if len(components) > 0 {
+		if err := b.savePaginationState(state); err != nil {
+			log.Printf("Error saving pagination state: %v", err)
+			components = nil
+		}
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    &page.Content,
+		Embeds:     &page.Embeds,
+		Components: &components,
+	})
+	if err != nil {
+		log.Printf("Failed to edit interaction response: %v", err)
+	}
+}
+
+// handlePaginationComponent handles Previous/Next button presses on a paginated message
+func (b *Bot) handlePaginationComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Custom IDs look like paginate:<state id>:<prev|next>
+	parts := strings.Split(i.MessageComponentData().CustomID, ":")
+	if len(parts) != 3 {
+		return
+	}
+	stateID, direction := parts[1], parts[2]
+
+	state, err := b.loadPaginationState(stateID)
+	if err != nil {
+		b.respondEphemeral(s, i, "These results have expired. Please run the command again.")
+		return
+	}
+
+	if interactionUser(i).ID != state.UserID {
+		b.respondEphemeral(s, i, "Only the person who ran this command can change pages.")
+		return
+	}
+
+	// Acknowledge right away; fetching the next page may take longer than Discord's 3 second limit
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+	if err != nil {
+		log.Printf("Failed to defer component interaction: %v", err)
+		return
+	}
+
+	switch direction {
+	case "prev":
+		state.Page--
+	case "next":
+		state.Page++
+	}
+	state.Page = max(state.Page, 1)
+
+	page, err := b.renderPage(state)
+	if err != nil {
+		log.Printf("Error rendering %s page %d: %v", state.Kind, state.Page, err)
+		b.followupEphemeral(s, i, "Couldn't load that page, please try again.")
+		return
+	}
+
+	if err := b.savePaginationState(state); err != nil {
+		log.Printf("Error saving pagination state: %v", err)
+	}
+
+	components := paginationComponents(state, page)
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    &page.Content,
+		Embeds:     &page.Embeds,
+		Components: &components,
+	})
+	if err != nil {
+		log.Printf("Failed to update paginated message: %v", err)
+	}
+}
+
+// paginationComponents builds the Previous / page indicator / Next button row
+// Single-page results get no buttons
+func paginationComponents(state *paginationState, page *renderedPage) []discordgo.MessageComponent {
+	if page.LastPage <= 1 {
+		return []discordgo.MessageComponent{}
+	}
+
+	customID := func(action string) string {
+		return fmt.Sprintf("%s:%s:%s", paginationCustomIDPrefix, state.ID, action)
+	}
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Previous",
+					Style:    discordgo.SecondaryButton,
+					CustomID: customID("prev"),
+					Disabled: state.Page <= 1,
+				},
+				discordgo.Button{
+					Label:    fmt.Sprintf("Page %d/%d", state.Page, page.LastPage),
+					Style:    discordgo.SecondaryButton,
+					CustomID: customID("page"),
+					Disabled: true,
+				},
+				discordgo.Button{
+					Label:    "Next",
+					Style:    discordgo.PrimaryButton,
+					CustomID: customID("next"),
+					Disabled: state.Page >= page.LastPage,
+				},
+			},
+		},
+	}
+}
+
+// savePaginationState stores pagination state until it expires
+func (b *Bot) savePaginationState(state *paginationState) error {
+	return b.store.Set(context.Background(), paginationKeyPrefix+state.ID, state, paginationTTL)
+}
+
+// loadPaginationState loads pagination state
+func (b *Bot) loadPaginationState(stateID string) (*paginationState, error) {
+	var state paginationState
+	if err := b.store.Get(context.Background(), paginationKeyPrefix+stateID, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+
+	"discord-anime-bot/internal/services/anilist"
+	"discord-anime-bot/internal/types"
+)
+
+// callbackPage is the page shown in the browser after the AniList OAuth redirect
+var callbackPage = template.Must(template.New("callback").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body style="font-family: sans-serif; text-align: center; margin-top: 4em;">
+<h1>{{.Title}}</h1>
+<p>{{.Message}}</p>
+</body>
+</html>
+`))
+
+// newCallbackServer builds the HTTP server that receives AniList OAuth redirects
+func (b *Bot) newCallbackServer() *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+b.anilist.CallbackPath(), b.handleAniListCallback)
+
+	return &http.Server{
+		Addr:              ":" + b.config.HTTPPort,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// startCallbackServer serves OAuth callbacks in the background until stopCallbackServer is called
+func (b *Bot) startCallbackServer() {
+	if b.callbackServer == nil {
+		return
+	}
+
+	go func() {
+		log.Printf("Listening for AniList OAuth callbacks on %s", b.callbackServer.Addr)
+		if err := b.callbackServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("OAuth callback server stopped: %v", err)
+		}
+	}()
+}
+
+// stopCallbackServer gracefully shuts down the OAuth callback server
+func (b *Bot) stopCallbackServer() {
+	if b.callbackServer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := b.callbackServer.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down OAuth callback server: %v", err)
+	}
+}
+
+// handleAniListCallback completes an account link started with /anime link
+func (b *Bot) handleAniListCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("error") != "" {
+		renderCallbackPage(w, http.StatusBadRequest, "Linking cancelled", "AniList did not authorize the link. Run /anime link in Discord to try again.")
+		return
+	}
+
+	state, code := query.Get("state"), query.Get("code")
+	if state == "" || code == "" {
+		renderCallbackPage(w, http.StatusBadRequest, "Invalid link", "This page must be opened from the link given by /anime link.")
+		return
+	}
+
+	discordUserID, account, err := b.anilist.CompleteLink(r.Context(), state, code)
+	if errors.Is(err, anilist.ErrInvalidState) {
+		renderCallbackPage(w, http.StatusBadRequest, "Link expired", "This authorization link is invalid or has expired. Run /anime link in Discord to get a new one.")
+		return
+	}
+	if err != nil {
+		log.Printf("Error completing AniList link: %v", err)
+		renderCallbackPage(w, http.StatusInternalServerError, "Linking failed", "Something went wrong while linking your AniList account. Please try again later.")
+		return
+	}
+
+	renderCallbackPage(w, http.StatusOK, "AniList account linked", fmt.Sprintf("Linked AniList account %s. You can close this page and return to Discord.", account.Name))
+	b.sendLinkConfirmation(discordUserID, account)
+}
+
+// renderCallbackPage writes a small HTML status page
+func renderCallbackPage(w http.ResponseWriter, status int, title, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := callbackPage.Execute(w, map[string]string{"Title": title, "Message": message}); err != nil {
+		log.Printf("Error rendering callback page: %v", err)
+	}
+}
+
+// sendLinkConfirmation tells the user by DM that their account was linked
+func (b *Bot) sendLinkConfirmation(discordUserID string, account *types.AniListAccount) {
+	channel, err := b.session.UserChannelCreate(discordUserID)
+	if err != nil {
+		log.Printf("Error opening DM with user %s: %v", discordUserID, err)
+		return
+	}
+
+	message := fmt.Sprintf("Linked your AniList account **[%s](%s)**. Use `/anime watchlist action:import` to copy your AniList list into your watchlist, or `action:export` to push your watchlist to AniList. Watchlist changes made in Discord are synced to AniList from now on.", account.Name, account.SiteURL)
+	if _, err := b.session.ChannelMessageSend(channel.ID, message); err != nil {
+		log.Printf("Error sending link confirmation to user %s: %v", discordUserID, err)
+	}
+}
@@ -0,0 +1,208 @@
+package admin
+
+import (
+	"time"
+
+	"discord-anime-bot/internal/services/settings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ConfigCommandName is the name of the guild configuration command
+// It is a top-level command rather than an /anime subcommand because Discord only applies
+// DefaultMemberPermissions to whole commands
+const ConfigCommandName = "anime-config"
+
+// GetConfigCommand returns the guild configuration command, restricted to members who can manage the server
+func GetConfigCommand() *discordgo.ApplicationCommand {
+	manageGuild := int64(discordgo.PermissionManageGuild)
+
+	var commandChoices []*discordgo.ApplicationCommandOptionChoice
+	for _, name := range settings.ToggleableCommands {
+		commandChoices = append(commandChoices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  name,
+			Value: name,
+		})
+	}
+
+	return &discordgo.ApplicationCommand{
+		Name:                     ConfigCommandName,
+		Description:              "Configure the anime bot for this server",
+		DefaultMemberPermissions: &manageGuild,
+		Contexts:                 &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild},
+		IntegrationTypes:         &[]discordgo.ApplicationIntegrationType{discordgo.ApplicationIntegrationGuildInstall},
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "view",
+				Description: "Show the current server settings",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "channel",
+				Description: "Set the channel episode alerts are posted in (leave empty to use the channel they were set up in)",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "channel",
+						Description:  "Alert channel",
+						Required:     false,
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "role",
+				Description: "Set a role to ping with episode alerts (leave empty to stop pinging)",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionRole,
+						Name:        "role",
+						Description: "Role to ping",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "language",
+				Description: "Set the preferred language for anime titles",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "language",
+						Description: "Title language",
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "English (falls back to Romaji)",
+								Value: settings.TitleLanguageEnglish,
+							},
+							{
+								Name:  "Romaji",
+								Value: settings.TitleLanguageRomaji,
+							},
+							{
+								Name:  "Native",
+								Value: settings.TitleLanguageNative,
+							},
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "timezone",
+				Description: "Set the server's time zone",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "timezone",
+						Description: "IANA time zone name, e.g. Europe/Berlin or Asia/Tokyo",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "command",
+				Description: "Enable or disable an /anime subcommand on this server",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Subcommand",
+						Required:    true,
+						Choices:     commandChoices,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "enabled",
+						Description: "Whether the subcommand can be used",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "find",
+				Description: "Allow or disallow AI-powered /anime find on this server",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "enabled",
+						Description: "Whether AI find can be used",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "digest",
+				Description: "Post a daily or weekly airing schedule digest to a channel",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "frequency",
+						Description: "How often to post the digest",
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "Daily (next 24 hours)",
+								Value: settings.DigestDaily,
+							},
+							{
+								Name:  "Weekly (next 7 days by weekday)",
+								Value: settings.DigestWeekly,
+							},
+							{
+								Name:  "Off",
+								Value: "off",
+							},
+						},
+					},
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "channel",
+						Description:  "Channel to post the digest in (defaults to this channel)",
+						Required:     false,
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "time",
+						Description: "Local time to post at in 24-hour HH:MM format (default " + settings.DefaultDigestTime + ")",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "weekday",
+						Description: "Day to post weekly digests on (default Monday)",
+						Required:    false,
+						Choices:     weekdayChoices(),
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "deliveries",
+				Description: "Show episode alerts that could not be delivered, with their errors",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "reset",
+				Description: "Restore the default settings",
+			},
+		},
+	}
+}
+
+// weekdayChoices returns a choice per weekday, valued by time.Weekday
+func weekdayChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, 7)
+	for day := time.Monday; day <= time.Saturday; day++ {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: day.String(), Value: int(day)})
+	}
+	return append(choices, &discordgo.ApplicationCommandOptionChoice{Name: time.Sunday.String(), Value: int(time.Sunday)})
+}
@@ -12,17 +12,23 @@ func GetAnimeCommandOptions(cfg *config.Config) []*discordgo.ApplicationCommandO
 		GetSearchCommandOption(),
 		GetNextCommandOption(),
 		GetNotifyCommandOption(),
-		GetWatchlistCommandOption(),
+		GetWatchlistCommandOption(cfg),
 		GetReleaseCommandOption(),
 		GetSeasonCommandOption(),
+		GetScheduleCommandOption(),
 	}
 
-	// Conditionally add the find command if OpenAI is enabled
-	if cfg.IsOpenAIEnabled {
+	// Conditionally add the find command if any AI provider is enabled
+	if cfg.IsAIEnabled {
 		// Prepend find command to the beginning for better UX
 		commandOptions = append([]*discordgo.ApplicationCommandOption{GetFindCommandOption()}, commandOptions...)
 	}
 
+	// Account linking needs an AniList OAuth app
+	if cfg.IsAniListLinkEnabled {
+		commandOptions = append(commandOptions, GetLinkCommandOption())
+	}
+
 	// Add help command at the end
 	commandOptions = append(commandOptions, GetHelpCommandOption())
 
@@ -30,10 +36,20 @@ func GetAnimeCommandOptions(cfg *config.Config) []*discordgo.ApplicationCommandO
 }
 
 // GetAnimeCommand returns the complete anime command definition
+// The command can be installed to servers or to users, and is usable in servers, the bot's DMs and group DMs
 func GetAnimeCommand(cfg *config.Config) *discordgo.ApplicationCommand {
 	return &discordgo.ApplicationCommand{
 		Name:        "anime",
 		Description: "Anime-related commands",
 		Options:     GetAnimeCommandOptions(cfg),
+		Contexts: &[]discordgo.InteractionContextType{
+			discordgo.InteractionContextGuild,
+			discordgo.InteractionContextBotDM,
+			discordgo.InteractionContextPrivateChannel,
+		},
+		IntegrationTypes: &[]discordgo.ApplicationIntegrationType{
+			discordgo.ApplicationIntegrationGuildInstall,
+			discordgo.ApplicationIntegrationUserInstall,
+		},
 	}
 }
\ No newline at end of file
@@ -0,0 +1,26 @@
+package anime
+
+import "github.com/bwmarrin/discordgo"
+
+// GetLinkCommandOption returns the link command option
+func GetLinkCommandOption() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        "link",
+		Description: "Link your AniList account to sync your watchlist",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "action",
+				Description: "Action to perform (shows your link status by default)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{
+						Name:  "Unlink AniList account",
+						Value: "unlink",
+					},
+				},
+			},
+		},
+	}
+}
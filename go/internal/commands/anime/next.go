@@ -10,10 +10,11 @@ func GetNextCommandOption() *discordgo.ApplicationCommandOption {
 		Description: "Get next episode information for an anime",
 		Options: []*discordgo.ApplicationCommandOption{
 			{
-				Type:        discordgo.ApplicationCommandOptionInteger,
-				Name:        "id",
-				Description: "The AniList ID of the anime",
-				Required:    true,
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "id",
+				Description:  "Anime title or AniList ID",
+				Required:     true,
+				Autocomplete: true,
 			},
 		},
 	}
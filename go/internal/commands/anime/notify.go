@@ -12,25 +12,51 @@ func GetNotifyCommandOption() *discordgo.ApplicationCommandOption {
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "action",
-				Description: "Action to perform (add or cancel)",
+				Description: "Action to perform (add, subscribe, cancel or watchlist auto-notify)",
 				Required:    false,
 				Choices: []*discordgo.ApplicationCommandOptionChoice{
 					{
 						Name:  "Add notification",
 						Value: "add",
 					},
+					{
+						Name:  "Subscribe to every episode",
+						Value: "subscribe",
+					},
 					{
 						Name:  "Cancel notification",
 						Value: "cancel",
 					},
+					{
+						Name:  "Auto-notify for my watchlist",
+						Value: "auto",
+					},
+					{
+						Name:  "Stop watchlist auto-notify",
+						Value: "auto-off",
+					},
 				},
 			},
 			{
-				Type:        discordgo.ApplicationCommandOptionInteger,
-				Name:        "id",
-				Description: "AniList ID of the anime (required for add/cancel)",
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "id",
+				Description:  "Anime title or AniList ID (required for add/subscribe/cancel)",
+				Required:     false,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "dm",
+				Description: "Send the alert to your DMs instead of this channel",
 				Required:    false,
 			},
+			{
+				Type:         discordgo.ApplicationCommandOptionChannel,
+				Name:         "channel",
+				Description:  "Channel for watchlist auto-notify alerts (default: this channel)",
+				Required:     false,
+				ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews},
+			},
 		},
 	}
 }
\ No newline at end of file
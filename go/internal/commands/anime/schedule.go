@@ -0,0 +1,48 @@
+package anime
+
+import "github.com/bwmarrin/discordgo"
+
+// GetScheduleCommandOption returns the schedule command option
+func GetScheduleCommandOption() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        "schedule",
+		Description: "Show episodes airing on a day or within the next hours (next 24 hours by default)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "day",
+				Description: "Day to show",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Today", Value: "today"},
+					{Name: "Tomorrow", Value: "tomorrow"},
+					{Name: "Monday", Value: "monday"},
+					{Name: "Tuesday", Value: "tuesday"},
+					{Name: "Wednesday", Value: "wednesday"},
+					{Name: "Thursday", Value: "thursday"},
+					{Name: "Friday", Value: "friday"},
+					{Name: "Saturday", Value: "saturday"},
+					{Name: "Sunday", Value: "sunday"},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "hours",
+				Description: "Show episodes airing within the next number of hours (max 168)",
+				Required:    false,
+				MinValue:    &scheduleMinHours,
+				MaxValue:    168,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "timezone",
+				Description: "IANA time zone used to group days, e.g. Europe/Berlin (defaults to the server's)",
+				Required:    false,
+			},
+		},
+	}
+}
+
+// scheduleMinHours is the smallest hours window; MinValue needs an addressable value
+var scheduleMinHours = 1.0
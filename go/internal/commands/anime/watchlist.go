@@ -1,10 +1,19 @@
 package anime
 
-import "github.com/bwmarrin/discordgo"
+import (
+	"discord-anime-bot/internal/config"
+	"discord-anime-bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// watchlistMinValue is the lowest progress and score accepted by the watchlist options
+var watchlistMinValue = 0.0
 
 // GetWatchlistCommandOption returns the watchlist command option
-func GetWatchlistCommandOption() *discordgo.ApplicationCommandOption {
-	return &discordgo.ApplicationCommandOption{
+// The AniList import and export actions are only offered when account linking is configured
+func GetWatchlistCommandOption(cfg *config.Config) *discordgo.ApplicationCommandOption {
+	option := &discordgo.ApplicationCommandOption{
 		Type:        discordgo.ApplicationCommandOptionSubCommand,
 		Name:        "watchlist",
 		Description: "Manage your anime watchlist (shows list by default)",
@@ -12,7 +21,7 @@ func GetWatchlistCommandOption() *discordgo.ApplicationCommandOption {
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "action",
-				Description: "Action to perform (add or remove)",
+				Description: "Action to perform (add, remove or update an entry)",
 				Required:    false,
 				Choices: []*discordgo.ApplicationCommandOptionChoice{
 					{
@@ -23,14 +32,99 @@ func GetWatchlistCommandOption() *discordgo.ApplicationCommandOption {
 						Name:  "Remove from watchlist",
 						Value: "remove",
 					},
+					{
+						Name:  "Mark next episode watched",
+						Value: "watched",
+					},
+					{
+						Name:  "Set episodes watched",
+						Value: "progress",
+					},
+					{
+						Name:  "Change status",
+						Value: "status",
+					},
+					{
+						Name:  "Set score",
+						Value: "score",
+					},
+				},
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "id",
+				Description:  "Anime title or AniList ID (required for every action except import/export)",
+				Required:     false,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "sort",
+				Description: "How to sort the list (default: next airing)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{
+						Name:  "Next airing",
+						Value: "airing",
+					},
+					{
+						Name:  "Title",
+						Value: "title",
+					},
+					{
+						Name:  "Recently added",
+						Value: "added",
+					},
+					{
+						Name:  "Recently updated",
+						Value: "updated",
+					},
 				},
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "status",
+				Description: "Status to set (add/status), or only list entries with this status",
+				Required:    false,
+				Choices:     watchlistStatusChoices(),
+			},
 			{
 				Type:        discordgo.ApplicationCommandOptionInteger,
-				Name:        "id",
-				Description: "AniList ID of the anime (required for add/remove)",
+				Name:        "progress",
+				Description: "Episodes watched (progress action)",
 				Required:    false,
+				MinValue:    &watchlistMinValue,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionNumber,
+				Name:        "score",
+				Description: "Score out of 10, 0 to clear (score action)",
+				Required:    false,
+				MinValue:    &watchlistMinValue,
+				MaxValue:    10,
 			},
 		},
 	}
-}
\ No newline at end of file
+
+	if cfg.IsAniListLinkEnabled {
+		action := option.Options[0]
+		action.Choices = append(action.Choices,
+			&discordgo.ApplicationCommandOptionChoice{Name: "Import from AniList", Value: "import"},
+			&discordgo.ApplicationCommandOptionChoice{Name: "Export to AniList", Value: "export"},
+		)
+	}
+
+	return option
+}
+
+// watchlistStatusChoices returns a choice for every watchlist status
+func watchlistStatusChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(types.WatchlistStatuses))
+	for _, status := range types.WatchlistStatuses {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  types.WatchlistStatusNames[status],
+			Value: status,
+		})
+	}
+	return choices
+}
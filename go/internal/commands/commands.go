@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"discord-anime-bot/internal/commands/admin"
 	"discord-anime-bot/internal/commands/anime"
 	"discord-anime-bot/internal/config"
 	"github.com/bwmarrin/discordgo"
@@ -10,5 +11,6 @@ import (
 func GetAllCommands(cfg *config.Config) []*discordgo.ApplicationCommand {
 	return []*discordgo.ApplicationCommand{
 		anime.GetAnimeCommand(cfg),
+		admin.GetConfigCommand(),
 	}
 }
\ No newline at end of file
@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+
+	"discord-anime-bot/internal/config"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Command registration scopes
+const (
+	ScopeGlobal = "global"
+	ScopeGuild  = "guild"
+)
+
+// Registrar keeps the application commands registered with Discord in sync with GetAllCommands
+type Registrar struct {
+	session *discordgo.Session
+	config  *config.Config
+
+	mu     sync.Mutex
+	synced map[string]bool // scopes ("" for global, otherwise guild IDs) already synced this session
+}
+
+// NewRegistrar creates a new command registrar
+func NewRegistrar(session *discordgo.Session, cfg *config.Config) *Registrar {
+	return &Registrar{
+		session: session,
+		config:  cfg,
+		synced:  make(map[string]bool),
+	}
+}
+
+// IsGlobal reports whether commands are registered globally rather than per guild
+func (r *Registrar) IsGlobal() bool {
+	return r.config.CommandScope != ScopeGuild
+}
+
+// GuildAllowed reports whether commands should be registered to the given guild
+// An empty allow-list means every guild the bot is in
+func (r *Registrar) GuildAllowed(guildID string) bool {
+	if r.IsGlobal() {
+		return false
+	}
+	return len(r.config.CommandGuildIDs) == 0 || slices.Contains(r.config.CommandGuildIDs, guildID)
+}
+
+// SyncGlobal syncs the global application commands
+func (r *Registrar) SyncGlobal() error {
+	return r.sync("", GetAllCommands(r.config))
+}
+
+// SyncGuild syncs the application commands of a single guild
+func (r *Registrar) SyncGuild(guildID string) error {
+	return r.sync(guildID, GetAllCommands(r.config))
+}
+
+// ClearGuild removes the application commands registered to a guild that should not have any,
+// such as those left behind by the guild scope after switching to global commands, which would
+// otherwise show up twice
+func (r *Registrar) ClearGuild(guildID string) error {
+	return r.sync(guildID, []*discordgo.ApplicationCommand{})
+}
+
+// sync diffs the registered commands against the desired ones and bulk overwrites them when they differ
+func (r *Registrar) sync(guildID string, desired []*discordgo.ApplicationCommand) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.synced[guildID] {
+		return nil
+	}
+
+	scope := "global"
+	if guildID != "" {
+		scope = "guild " + guildID
+	}
+
+	appID := r.session.State.User.ID
+	existing, err := r.session.ApplicationCommands(appID, guildID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s commands: %w", scope, err)
+	}
+
+	diff := diffCommands(existing, desired)
+	if diff.empty() {
+		log.Printf("Commands for %s are up to date", scope)
+		r.synced[guildID] = true
+		return nil
+	}
+
+	log.Printf("Syncing commands for %s (create: %v, update: %v, delete: %v)", scope, diff.create, diff.update, diff.remove)
+
+	if _, err := r.session.ApplicationCommandBulkOverwrite(appID, guildID, desired); err != nil {
+		return fmt.Errorf("failed to overwrite %s commands: %w", scope, err)
+	}
+
+	log.Printf("Successfully synced %d commands for %s", len(desired), scope)
+	r.synced[guildID] = true
+	return nil
+}
+
+// commandDiff lists the command names that need to be created, updated or deleted
+type commandDiff struct {
+	create []string
+	update []string
+	remove []string
+}
+
+func (d commandDiff) empty() bool {
+	return len(d.create) == 0 && len(d.update) == 0 && len(d.remove) == 0
+}
+
+// diffCommands compares the commands registered with Discord against the desired commands
+func diffCommands(existing, desired []*discordgo.ApplicationCommand) commandDiff {
+	var diff commandDiff
+
+	registered := make(map[string]*discordgo.ApplicationCommand, len(existing))
+	for _, cmd := range existing {
+		registered[cmd.Name] = cmd
+	}
+
+	for _, cmd := range desired {
+		current, exists := registered[cmd.Name]
+		switch {
+		case !exists:
+			diff.create = append(diff.create, cmd.Name)
+		case !commandsEqual(current, cmd):
+			diff.update = append(diff.update, cmd.Name)
+		}
+		delete(registered, cmd.Name)
+	}
+
+	for name := range registered {
+		diff.remove = append(diff.remove, name)
+	}
+
+	return diff
+}
+
+// commandsEqual reports whether a registered command matches the desired definition
+// Fields Discord fills in with defaults are only compared when the desired command sets them
+func commandsEqual(current, desired *discordgo.ApplicationCommand) bool {
+	normalizedCurrent := normalizeCommand(current)
+	normalizedDesired := normalizeCommand(desired)
+
+	if desired.Contexts == nil {
+		normalizedCurrent.Contexts = nil
+	}
+	if desired.IntegrationTypes == nil {
+		normalizedCurrent.IntegrationTypes = nil
+	}
+
+	currentJSON, err := json.Marshal(normalizedCurrent)
+	if err != nil {
+		return false
+	}
+	desiredJSON, err := json.Marshal(normalizedDesired)
+	if err != nil {
+		return false
+	}
+
+	return string(currentJSON) == string(desiredJSON)
+}
+
+// normalizeCommand copies the user-defined fields of a command, dropping IDs and versions
+func normalizeCommand(cmd *discordgo.ApplicationCommand) *discordgo.ApplicationCommand {
+	commandType := cmd.Type
+	if commandType == 0 {
+		commandType = discordgo.ChatApplicationCommand
+	}
+
+	return &discordgo.ApplicationCommand{
+		Type:                     commandType,
+		Name:                     cmd.Name,
+		Description:              cmd.Description,
+		DefaultMemberPermissions: cmd.DefaultMemberPermissions,
+		NSFW:                     cmd.NSFW,
+		Contexts:                 cmd.Contexts,
+		IntegrationTypes:         cmd.IntegrationTypes,
+		Options:                  normalizeOptions(cmd.Options),
+	}
+}
+
+// normalizeOptions copies options, treating empty and missing lists the same way
+func normalizeOptions(options []*discordgo.ApplicationCommandOption) []*discordgo.ApplicationCommandOption {
+	if len(options) == 0 {
+		return nil
+	}
+
+	normalized := make([]*discordgo.ApplicationCommandOption, 0, len(options))
+	for _, option := range options {
+		copied := *option
+		copied.NameLocalizations = nil
+		copied.DescriptionLocalizations = nil
+		copied.Options = normalizeOptions(option.Options)
+		if len(copied.ChannelTypes) == 0 {
+			copied.ChannelTypes = nil
+		}
+		if len(copied.Choices) == 0 {
+			copied.Choices = nil
+		}
+		normalized = append(normalized, &copied)
+	}
+
+	return normalized
+}
@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"slices"
+	"testing"
+
+	"discord-anime-bot/internal/config"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// testCommand returns a command with one string option, like Discord would store it
+func testCommand(name string) *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        name,
+		Description: name + " command",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "query",
+				Description: "Search text",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// registered returns cmd as Discord reports it back, with IDs, version and defaults filled in
+func registered(cmd *discordgo.ApplicationCommand) *discordgo.ApplicationCommand {
+	copied := *cmd
+	copied.ID = "1"
+	copied.ApplicationID = "2"
+	copied.Version = "3"
+	copied.Type = discordgo.ChatApplicationCommand
+	if copied.Contexts == nil {
+		copied.Contexts = &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild}
+	}
+	if copied.IntegrationTypes == nil {
+		copied.IntegrationTypes = &[]discordgo.ApplicationIntegrationType{discordgo.ApplicationIntegrationGuildInstall}
+	}
+	return &copied
+}
+
+func TestDiffCommands(t *testing.T) {
+	withOption := func(option *discordgo.ApplicationCommandOption) *discordgo.ApplicationCommand {
+		cmd := testCommand("anime")
+		cmd.Options = append(cmd.Options, option)
+		return cmd
+	}
+	withContexts := func(contexts ...discordgo.InteractionContextType) *discordgo.ApplicationCommand {
+		cmd := testCommand("anime")
+		cmd.Contexts = &contexts
+		return cmd
+	}
+	withIntegrationTypes := func(types ...discordgo.ApplicationIntegrationType) *discordgo.ApplicationCommand {
+		cmd := testCommand("anime")
+		cmd.IntegrationTypes = &types
+		return cmd
+	}
+
+	tests := []struct {
+		name     string
+		existing []*discordgo.ApplicationCommand
+		desired  []*discordgo.ApplicationCommand
+		want     commandDiff
+	}{
+		{
+			name:     "unchanged commands ignore IDs and defaults",
+			existing: []*discordgo.ApplicationCommand{registered(testCommand("anime"))},
+			desired:  []*discordgo.ApplicationCommand{testCommand("anime")},
+		},
+		{
+			name:     "new command",
+			existing: []*discordgo.ApplicationCommand{registered(testCommand("anime"))},
+			desired:  []*discordgo.ApplicationCommand{testCommand("anime"), testCommand("anime-config")},
+			want:     commandDiff{create: []string{"anime-config"}},
+		},
+		{
+			name:     "removed command",
+			existing: []*discordgo.ApplicationCommand{registered(testCommand("anime")), registered(testCommand("old"))},
+			desired:  []*discordgo.ApplicationCommand{testCommand("anime")},
+			want:     commandDiff{remove: []string{"old"}},
+		},
+		{
+			name:     "every command removed",
+			existing: []*discordgo.ApplicationCommand{registered(testCommand("anime"))},
+			desired:  []*discordgo.ApplicationCommand{},
+			want:     commandDiff{remove: []string{"anime"}},
+		},
+		{
+			name:     "changed description",
+			existing: []*discordgo.ApplicationCommand{registered(testCommand("anime"))},
+			desired: []*discordgo.ApplicationCommand{func() *discordgo.ApplicationCommand {
+				cmd := testCommand("anime")
+				cmd.Description = "Something else"
+				return cmd
+			}()},
+			want: commandDiff{update: []string{"anime"}},
+		},
+		{
+			name:     "added option",
+			existing: []*discordgo.ApplicationCommand{registered(testCommand("anime"))},
+			desired: []*discordgo.ApplicationCommand{withOption(&discordgo.ApplicationCommandOption{
+				Type: discordgo.ApplicationCommandOptionInteger, Name: "year", Description: "Year",
+			})},
+			want: commandDiff{update: []string{"anime"}},
+		},
+		{
+			name:     "option made optional",
+			existing: []*discordgo.ApplicationCommand{registered(testCommand("anime"))},
+			desired: []*discordgo.ApplicationCommand{func() *discordgo.ApplicationCommand {
+				cmd := testCommand("anime")
+				cmd.Options[0].Required = false
+				return cmd
+			}()},
+			want: commandDiff{update: []string{"anime"}},
+		},
+		{
+			name: "changed choices",
+			existing: []*discordgo.ApplicationCommand{registered(withOption(&discordgo.ApplicationCommandOption{
+				Type: discordgo.ApplicationCommandOptionString, Name: "season", Description: "Season",
+				Choices: []*discordgo.ApplicationCommandOptionChoice{{Name: "Winter", Value: "WINTER"}},
+			}))},
+			desired: []*discordgo.ApplicationCommand{withOption(&discordgo.ApplicationCommandOption{
+				Type: discordgo.ApplicationCommandOptionString, Name: "season", Description: "Season",
+				Choices: []*discordgo.ApplicationCommandOptionChoice{{Name: "Winter", Value: "WINTER"}, {Name: "Spring", Value: "SPRING"}},
+			})},
+			want: commandDiff{update: []string{"anime"}},
+		},
+		{
+			name: "localizations and empty lists are ignored",
+			existing: []*discordgo.ApplicationCommand{registered(withOption(&discordgo.ApplicationCommandOption{
+				Type: discordgo.ApplicationCommandOptionChannel, Name: "channel", Description: "Channel",
+				NameLocalizations: map[discordgo.Locale]string{discordgo.German: "kanal"},
+				ChannelTypes:      []discordgo.ChannelType{},
+				Choices:           []*discordgo.ApplicationCommandOptionChoice{},
+			}))},
+			desired: []*discordgo.ApplicationCommand{withOption(&discordgo.ApplicationCommandOption{
+				Type: discordgo.ApplicationCommandOptionChannel, Name: "channel", Description: "Channel",
+			})},
+		},
+		{
+			name:     "changed contexts",
+			existing: []*discordgo.ApplicationCommand{registered(testCommand("anime"))},
+			desired: []*discordgo.ApplicationCommand{withContexts(
+				discordgo.InteractionContextGuild, discordgo.InteractionContextBotDM, discordgo.InteractionContextPrivateChannel)},
+			want: commandDiff{update: []string{"anime"}},
+		},
+		{
+			name:     "same contexts",
+			existing: []*discordgo.ApplicationCommand{registered(withContexts(discordgo.InteractionContextGuild, discordgo.InteractionContextBotDM))},
+			desired:  []*discordgo.ApplicationCommand{withContexts(discordgo.InteractionContextGuild, discordgo.InteractionContextBotDM)},
+		},
+		{
+			name:     "changed integration types",
+			existing: []*discordgo.ApplicationCommand{registered(testCommand("anime"))},
+			desired: []*discordgo.ApplicationCommand{withIntegrationTypes(
+				discordgo.ApplicationIntegrationGuildInstall, discordgo.ApplicationIntegrationUserInstall)},
+			want: commandDiff{update: []string{"anime"}},
+		},
+		{
+			name: "changed default member permissions",
+			existing: []*discordgo.ApplicationCommand{registered(func() *discordgo.ApplicationCommand {
+				cmd := testCommand("anime-config")
+				permissions := int64(discordgo.PermissionManageGuild)
+				cmd.DefaultMemberPermissions = &permissions
+				return cmd
+			}())},
+			desired: []*discordgo.ApplicationCommand{func() *discordgo.ApplicationCommand {
+				cmd := testCommand("anime-config")
+				permissions := int64(discordgo.PermissionAdministrator)
+				cmd.DefaultMemberPermissions = &permissions
+				return cmd
+			}()},
+			want: commandDiff{update: []string{"anime-config"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := diffCommands(test.existing, test.desired)
+			slices.Sort(got.create)
+			slices.Sort(got.update)
+			slices.Sort(got.remove)
+
+			if !slices.Equal(got.create, test.want.create) || !slices.Equal(got.update, test.want.update) || !slices.Equal(got.remove, test.want.remove) {
+				t.Errorf("diffCommands = %+v, want %+v", got, test.want)
+			}
+			if got.empty() != test.want.empty() {
+				t.Errorf("empty = %v, want %v", got.empty(), test.want.empty())
+			}
+		})
+	}
+}
+
+func TestCommandsEqualForEveryDefinedCommand(t *testing.T) {
+	// A freshly registered set must compare equal to itself, or every start would overwrite it
+	for _, cmd := range GetAllCommands(&config.Config{IsAIEnabled: true, IsAniListLinkEnabled: true}) {
+		if !commandsEqual(registered(cmd), cmd) {
+			t.Errorf("command %s does not equal its registered copy", cmd.Name)
+		}
+	}
+}
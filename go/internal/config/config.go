@@ -3,37 +3,103 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all configuration values for the bot
 type Config struct {
-	DiscordToken    string
-	ChannelID       string
-	AniListAPI      string
-	OpenAIAPIKey    string
-	ClaudeAPIKey    string
-	IsOpenAIEnabled bool
-	IsClaudeEnabled bool
-	IsAIEnabled     bool
-	UseOpenAI       bool // true if OpenAI should be used, false if Claude should be used
-	RedisURL        string
+	DiscordToken      string
+	AniListAPI        string
+	AniListTimeout    time.Duration // per-request timeout for AniList calls
+	AniListMaxRetries int           // retries for rate-limited or failed AniList calls
+	// Redis cache lifetimes for AniList responses, 0 disables caching for that kind
+	AniListMediaCacheTTL  time.Duration
+	AniListSearchCacheTTL time.Duration
+	AniListPageCacheTTL   time.Duration
+	// AniList OAuth app used by /anime link; linking is disabled unless all of these are set
+	AniListClientID      string
+	AniListClientSecret  string
+	AniListRedirectURL   string
+	AniListTokenKey      string // secret used to encrypt stored AniList access tokens
+	IsAniListLinkEnabled bool
+	HTTPPort             string // port of the HTTP server that receives OAuth callbacks
+	MetricsAddr          string // listen address of the internal expvar metrics server, empty disables it
+	OpenAIAPIKey         string
+	ClaudeAPIKey         string
+	IsOpenAIEnabled      bool
+	IsClaudeEnabled      bool
+	IsAIEnabled          bool
+	AIProvider           string   // primary AI provider for find ("openai" or "claude")
+	AIFallback           []string // providers tried in order when the primary fails
+	OpenAIModel          string
+	ClaudeModel          string
+	RedisURL             string
+	CommandScope         string   // "global" (default) or "guild"
+	CommandGuildIDs      []string // guild allow-list when CommandScope is "guild", empty means every guild
+	// How often pending notifications are checked against AniList's current airing times,
+	// whether subscribers are told when an episode is delayed, and how long after airing
+	// an alert missed while the bot was down is still sent on startup (0 disables catch-up)
+	NotificationReconcileInterval time.Duration
+	NotificationDelayNotices      bool
+	NotificationCatchUpWindow     time.Duration
+	// Backend for bot data, "redis" (default) or "memory"; the memory backend is optionally
+	// snapshotted to a local file
+	StorageBackend          string
+	StorageSnapshotPath     string
+	StorageSnapshotInterval time.Duration
 }
 
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	cfg := &Config{
-		DiscordToken: getEnv("DISCORD_BOT_TOKEN"),
-		ChannelID:    getEnv("CHANNEL_ID"),
-		AniListAPI:   getEnv("ANILIST_API"),
-		OpenAIAPIKey: getEnvOptional("OPENAI_API_KEY"),
-		ClaudeAPIKey: getEnvOptional("CLAUDE_API_KEY"),
-		RedisURL:     getEnvWithDefault("REDIS_URL", "redis://localhost:6379"),
+		DiscordToken:          getEnv("DISCORD_BOT_TOKEN"),
+		AniListAPI:            getEnv("ANILIST_API"),
+		AniListTimeout:        getEnvDuration("ANILIST_TIMEOUT", 10*time.Second),
+		AniListMaxRetries:     getEnvInt("ANILIST_MAX_RETRIES", 3),
+		AniListMediaCacheTTL:  getEnvDuration("ANILIST_CACHE_MEDIA_TTL", time.Hour),
+		AniListSearchCacheTTL: getEnvDuration("ANILIST_CACHE_SEARCH_TTL", 6*time.Hour),
+		AniListPageCacheTTL:   getEnvDuration("ANILIST_CACHE_PAGE_TTL", 30*time.Minute),
+		AniListClientID:       os.Getenv("ANILIST_CLIENT_ID"),
+		AniListClientSecret:   os.Getenv("ANILIST_CLIENT_SECRET"),
+		AniListRedirectURL:    os.Getenv("ANILIST_REDIRECT_URL"),
+		AniListTokenKey:       os.Getenv("ANILIST_TOKEN_KEY"),
+		HTTPPort:              getEnvWithDefault("PORT", "8082"),
+		MetricsAddr:           getEnvWithDefault("METRICS_ADDR", "localhost:9090"),
+		OpenAIAPIKey:          getEnvOptional("OPENAI_API_KEY"),
+		ClaudeAPIKey:          getEnvOptional("CLAUDE_API_KEY"),
+		OpenAIModel:           getEnvWithDefault("OPENAI_MODEL", "gpt-5"),
+		ClaudeModel:           getEnvWithDefault("CLAUDE_MODEL", "claude-sonnet-4-5"),
+		RedisURL:              getEnvWithDefault("REDIS_URL", "redis://localhost:6379"),
+		CommandScope:          strings.ToLower(getEnvWithDefault("COMMAND_SCOPE", "global")),
+		CommandGuildIDs:       getEnvList("COMMAND_GUILD_IDS"),
+
+		NotificationReconcileInterval: getEnvDuration("NOTIFICATION_RECONCILE_INTERVAL", 15*time.Minute),
+		NotificationDelayNotices:      getEnvBool("NOTIFICATION_DELAY_NOTICES", true),
+		NotificationCatchUpWindow:     getEnvDuration("NOTIFICATION_CATCHUP_WINDOW", time.Hour),
+
+		StorageBackend:          strings.ToLower(getEnvWithDefault("STORAGE_BACKEND", "redis")),
+		StorageSnapshotPath:     os.Getenv("STORAGE_SNAPSHOT_PATH"),
+		StorageSnapshotInterval: getEnvDuration("STORAGE_SNAPSHOT_INTERVAL", time.Minute),
 	}
 
 	cfg.IsOpenAIEnabled = cfg.OpenAIAPIKey != ""
 	cfg.IsClaudeEnabled = cfg.ClaudeAPIKey != ""
 	cfg.IsAIEnabled = cfg.IsOpenAIEnabled || cfg.IsClaudeEnabled
-	cfg.UseOpenAI = cfg.IsOpenAIEnabled || (!cfg.IsOpenAIEnabled && !cfg.IsClaudeEnabled)
+	cfg.IsAniListLinkEnabled = cfg.AniListClientID != "" && cfg.AniListClientSecret != "" &&
+		cfg.AniListRedirectURL != "" && cfg.AniListTokenKey != ""
+
+	// Default to whichever provider is configured, preferring OpenAI, and fall back to the other one
+	defaultProvider, defaultFallback := "openai", "claude"
+	if !cfg.IsOpenAIEnabled && cfg.IsClaudeEnabled {
+		defaultProvider, defaultFallback = "claude", "openai"
+	}
+	cfg.AIProvider = strings.ToLower(getEnvWithDefault("AI_PROVIDER", defaultProvider))
+	cfg.AIFallback = getEnvList("AI_FALLBACK")
+	if os.Getenv("AI_FALLBACK") == "" {
+		cfg.AIFallback = []string{defaultFallback}
+	}
 
 	// Validate required environment variables
 	validateConfig(cfg)
@@ -65,22 +131,89 @@ func getEnvWithDefault(key, defaultValue string) string {
 	return value
 }
 
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: invalid duration %q for %s, using default %v", value, key, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: invalid number %q for %s, using default %d", value, key, defaultValue)
+		return defaultValue
+	}
+	return number
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: invalid boolean %q for %s, using default %t", value, key, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func validateConfig(cfg *Config) {
 	if cfg.DiscordToken == "" {
 		log.Fatal("DISCORD_BOT_TOKEN is not set in environment variables.")
 	}
-	if cfg.ChannelID == "" {
-		log.Fatal("CHANNEL_ID is not set in environment variables.")
-	}
 	if cfg.AniListAPI == "" {
 		log.Fatal("ANILIST_API is not set in environment variables.")
 	}
+	if cfg.NotificationReconcileInterval <= 0 {
+		log.Fatal("NOTIFICATION_RECONCILE_INTERVAL must be a positive duration.")
+	}
+	if cfg.NotificationCatchUpWindow < 0 {
+		log.Fatal("NOTIFICATION_CATCHUP_WINDOW must not be negative.")
+	}
+	if cfg.StorageBackend != "redis" && cfg.StorageBackend != "memory" {
+		log.Fatalf("STORAGE_BACKEND must be \"redis\" or \"memory\", got %q.", cfg.StorageBackend)
+	}
+	if cfg.CommandScope != "global" && cfg.CommandScope != "guild" {
+		log.Fatalf("COMMAND_SCOPE must be \"global\" or \"guild\", got %q.", cfg.CommandScope)
+	}
+
+	if cfg.IsAniListLinkEnabled {
+		log.Printf("AniList account linking enabled (callback %s)", cfg.AniListRedirectURL)
+	} else {
+		log.Println("Warning: ANILIST_CLIENT_ID, ANILIST_CLIENT_SECRET, ANILIST_REDIRECT_URL or ANILIST_TOKEN_KEY is not set. AniList account linking will be disabled.")
+	}
 
 	// AI logic
-	if cfg.IsOpenAIEnabled {
-		log.Println("OpenAI features enabled (find command available)")
-	} else if cfg.IsClaudeEnabled {
-		log.Println("Claude features enabled (find command available)")
+	for _, provider := range append([]string{cfg.AIProvider}, cfg.AIFallback...) {
+		if provider != "openai" && provider != "claude" {
+			log.Fatalf("Unknown AI provider %q in AI_PROVIDER/AI_FALLBACK. Use \"openai\" or \"claude\".", provider)
+		}
+	}
+	if cfg.IsAIEnabled {
+		log.Printf("AI features enabled with provider %s, fallback %v (find command available)", cfg.AIProvider, cfg.AIFallback)
 	} else {
 		log.Println("Warning: No AI features enabled (find command not available)")
 	}
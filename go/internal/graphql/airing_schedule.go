@@ -0,0 +1,30 @@
+package graphql
+
+// GetAiringScheduleQuery is the GraphQL query for getting episodes airing within a time window
+const GetAiringScheduleQuery = `
+	query ($page: Int, $perPage: Int, $airingAtGreater: Int, $airingAtLesser: Int) {
+		Page(page: $page, perPage: $perPage) {
+			airingSchedules(airingAt_greater: $airingAtGreater, airingAt_lesser: $airingAtLesser, sort: [TIME]) {
+				id
+				episode
+				airingAt
+				media {
+					id
+					title {
+						romaji
+						english
+						native
+					}
+					format
+					siteUrl
+					isAdult
+				}
+			}
+			pageInfo {
+				total
+				currentPage
+				lastPage
+				hasNextPage
+			}
+		}
+	}`
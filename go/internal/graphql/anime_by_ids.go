@@ -0,0 +1,29 @@
+package graphql
+
+// GetAnimeByIDsQuery is the GraphQL query for getting the details of several anime at once
+const GetAnimeByIDsQuery = `
+	query ($ids: [Int], $perPage: Int) {
+		Page(page: 1, perPage: $perPage) {
+			media(id_in: $ids, type: ANIME) {
+				id
+				title {
+					romaji
+					english
+					native
+				}
+				status
+				format
+				seasonYear
+				episodes
+				nextAiringEpisode {
+					episode
+					airingAt
+					timeUntilAiring
+				}
+				coverImage {
+					large
+				}
+				siteUrl
+			}
+		}
+	}`
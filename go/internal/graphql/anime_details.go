@@ -12,6 +12,7 @@ const GetAnimeDetailsQuery = `
 			}
 			status
 			format
+			seasonYear
 			episodes
 			nextAiringEpisode {
 				episode
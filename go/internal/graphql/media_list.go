@@ -0,0 +1,42 @@
+package graphql
+
+// GetViewerQuery is the GraphQL query for the AniList user an access token belongs to
+const GetViewerQuery = `
+	query {
+		Viewer {
+			id
+			name
+			siteUrl
+		}
+	}`
+
+// GetMediaListCollectionQuery is the GraphQL query for every anime on an AniList user's list
+// Scores are requested out of 10 regardless of the user's own score format
+const GetMediaListCollectionQuery = `
+	query ($userId: Int) {
+		MediaListCollection(userId: $userId, type: ANIME) {
+			lists {
+				isCustomList
+				entries {
+					mediaId
+					status
+					progress
+					score(format: POINT_10_DECIMAL)
+					createdAt
+					updatedAt
+				}
+			}
+		}
+	}`
+
+// SaveMediaListEntryMutation is the GraphQL mutation that creates or updates an entry on the viewer's list
+// scoreRaw is out of 100, which AniList converts to the user's score format
+const SaveMediaListEntryMutation = `
+	mutation ($mediaId: Int, $status: MediaListStatus, $progress: Int, $scoreRaw: Int) {
+		SaveMediaListEntry(mediaId: $mediaId, status: $status, progress: $progress, scoreRaw: $scoreRaw) {
+			id
+			mediaId
+			status
+			progress
+		}
+	}`
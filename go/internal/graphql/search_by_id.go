@@ -10,8 +10,10 @@ const SearchAnimeByIDQuery = `
 				english
 				native
 			}
+			synonyms
 			format
 			status
+			seasonYear
 			coverImage {
 				large
 			}
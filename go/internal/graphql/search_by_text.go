@@ -17,8 +17,10 @@ const SearchAnimeByTextQuery = `
 					english
 					native
 				}
+				synonyms
 				format
 				status
+				seasonYear
 				coverImage {
 					large
 				}
@@ -0,0 +1,98 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"discord-anime-bot/internal/types"
+)
+
+// Supported AI provider names, as used in AI_PROVIDER and AI_FALLBACK
+const (
+	ProviderOpenAI = "openai"
+	ProviderClaude = "claude"
+)
+
+// Recommender suggests anime titles matching a free-text description
+// Each AI provider has its own implementation
+type Recommender interface {
+	// Name returns the provider name used in logs and help text
+	Name() string
+	// Recommend returns anime recommendations for a description
+	Recommend(ctx context.Context, description string) (*Result, error)
+}
+
+// Result holds a provider's recommendations along with who produced them
+type Result struct {
+	// Attribution names the provider and model that answered, e.g. "Claude claude-sonnet-4-5"
+	Attribution     string
+	Recommendations []types.AIRecommendation
+}
+
+// promptTemplate is the prompt shared by every provider; %s is replaced with the user's description
+const promptTemplate = `Based on this description: "%s"
+
+Please recommend anime titles that match this description. Return your response as a JSON object with the following structure:
+{
+  "recommendations": [
+    {
+      "title": "Exact anime title",
+      "reason": "Brief explanation of why this matches",
+      "confidence": 0.95
+    }
+  ]
+}
+
+Guidelines:
+- Return 1-3 recommendations
+- Use exact anime titles (romaji or English)
+- Confidence should be between 0.0 and 1.0
+- Only return valid JSON, no other text
+- Focus on popular/well-known anime`
+
+// BuildPrompt fills the shared prompt template with a description
+func BuildPrompt(description string) string {
+	return fmt.Sprintf(promptTemplate, description)
+}
+
+// Chain is a Recommender that tries each provider in order until one succeeds
+type Chain struct {
+	providers []Recommender
+}
+
+// NewChain creates a Recommender that falls back through providers in the given order
+func NewChain(providers ...Recommender) *Chain {
+	return &Chain{providers: providers}
+}
+
+// Name returns the names of all providers in fallback order
+func (c *Chain) Name() string {
+	names := make([]string, 0, len(c.providers))
+	for _, provider := range c.providers {
+		names = append(names, provider.Name())
+	}
+	return strings.Join(names, " → ")
+}
+
+// Recommend asks each provider in turn, returning the first successful answer
+func (c *Chain) Recommend(ctx context.Context, description string) (*Result, error) {
+	if len(c.providers) == 0 {
+		return nil, errors.New("no AI providers configured")
+	}
+
+	var errs []error
+	for _, provider := range c.providers {
+		result, err := provider.Recommend(ctx, description)
+		if err == nil {
+			return result, nil
+		}
+
+		log.Printf("%s recommendation failed, trying next provider: %v", provider.Name(), err)
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+	}
+
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"discord-anime-bot/internal/types"
+)
+
+// fakeRecommender is a Recommender that returns a fixed result or error and counts its calls
+type fakeRecommender struct {
+	name   string
+	result *Result
+	err    error
+	calls  int
+}
+
+func (f *fakeRecommender) Name() string {
+	return f.name
+}
+
+func (f *fakeRecommender) Recommend(ctx context.Context, description string) (*Result, error) {
+	f.calls++
+	return f.result, f.err
+}
+
+func TestChainFallsThroughToNextProvider(t *testing.T) {
+	failing := &fakeRecommender{name: "openai", err: errors.New("rate limited")}
+	succeeding := &fakeRecommender{name: "claude", result: &Result{
+		Attribution:     "claude",
+		Recommendations: []types.AIRecommendation{{Title: "Attack on Titan", Confidence: 0.9}},
+	}}
+	unused := &fakeRecommender{name: "spare", result: &Result{Attribution: "spare"}}
+
+	result, err := NewChain(failing, succeeding, unused).Recommend(context.Background(), "titans")
+	if err != nil {
+		t.Fatalf("Recommend: %v", err)
+	}
+	if result.Attribution != "claude" {
+		t.Errorf("attribution = %q, want the first successful provider", result.Attribution)
+	}
+	if failing.calls != 1 || succeeding.calls != 1 {
+		t.Errorf("calls = %d, %d, want each provider up to the first success asked once", failing.calls, succeeding.calls)
+	}
+	if unused.calls != 0 {
+		t.Errorf("provider after the first success was asked %d times", unused.calls)
+	}
+}
+
+func TestChainReturnsFirstSuccess(t *testing.T) {
+	first := &fakeRecommender{name: "openai", result: &Result{Attribution: "openai"}}
+	second := &fakeRecommender{name: "claude", result: &Result{Attribution: "claude"}}
+
+	result, err := NewChain(first, second).Recommend(context.Background(), "titans")
+	if err != nil {
+		t.Fatalf("Recommend: %v", err)
+	}
+	if result.Attribution != "openai" || second.calls != 0 {
+		t.Errorf("got %q after asking the fallback %d times, want the primary's answer", result.Attribution, second.calls)
+	}
+}
+
+func TestChainJoinsErrorsWhenEveryProviderFails(t *testing.T) {
+	errOpenAI := errors.New("openai down")
+	errClaude := errors.New("claude down")
+	chain := NewChain(
+		&fakeRecommender{name: "openai", err: errOpenAI},
+		&fakeRecommender{name: "claude", err: errClaude},
+	)
+
+	_, err := chain.Recommend(context.Background(), "titans")
+	if !errors.Is(err, errOpenAI) || !errors.Is(err, errClaude) {
+		t.Errorf("err = %v, want both provider errors", err)
+	}
+}
+
+func TestChainWithoutProviders(t *testing.T) {
+	if _, err := NewChain().Recommend(context.Background(), "titans"); err == nil {
+		t.Error("Recommend with no providers succeeded, want an error")
+	}
+}
+
+func TestChainName(t *testing.T) {
+	chain := NewChain(&fakeRecommender{name: "openai"}, &fakeRecommender{name: "claude"})
+	if got := chain.Name(); got != "openai → claude" {
+		t.Errorf("Name = %q, want %q", got, "openai → claude")
+	}
+}
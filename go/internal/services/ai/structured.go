@@ -0,0 +1,146 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"discord-anime-bot/internal/types"
+)
+
+// MaxRepairAttempts is how many times a provider is asked to fix malformed output
+// before the recommendation fails
+const MaxRepairAttempts = 1
+
+// SchemaName names the structured output schema (OpenAI response format / Claude tool)
+const SchemaName = "anime_recommendations"
+
+// SchemaDescription describes the structured output to the model
+const SchemaDescription = "Anime recommendations matching the user's description"
+
+// SchemaProperties returns the JSON schema properties of the structured recommendations object
+func SchemaProperties() map[string]any {
+	return map[string]any{
+		"recommendations": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"title": map[string]any{
+						"type":        "string",
+						"description": "Exact anime title (romaji or English)",
+					},
+					"reason": map[string]any{
+						"type":        "string",
+						"description": "Brief explanation of why this matches",
+					},
+					"confidence": map[string]any{
+						"type":        "number",
+						"description": "How well the anime matches, between 0.0 and 1.0",
+					},
+				},
+				"required":             []string{"title", "reason", "confidence"},
+				"additionalProperties": false,
+			},
+		},
+	}
+}
+
+// SchemaRequired returns the required top-level properties of the structured recommendations object
+func SchemaRequired() []string {
+	return []string{"recommendations"}
+}
+
+// Schema returns the complete JSON schema of the structured recommendations object
+func Schema() map[string]any {
+	return map[string]any{
+		"type":                 "object",
+		"properties":           SchemaProperties(),
+		"required":             SchemaRequired(),
+		"additionalProperties": false,
+	}
+}
+
+// structuredResponse is the top-level object described by Schema
+type structuredResponse struct {
+	Recommendations []types.AIRecommendation `json:"recommendations"`
+}
+
+// DecodeRecommendations parses and validates a provider's structured output
+// It tolerates code fences and surrounding prose, and accepts a bare array as well as the schema object
+func DecodeRecommendations(content string) ([]types.AIRecommendation, error) {
+	payload := extractJSON(content)
+	if payload == "" {
+		return nil, errors.New("response does not contain JSON")
+	}
+
+	var recommendations []types.AIRecommendation
+	if strings.HasPrefix(payload, "[") {
+		if err := json.Unmarshal([]byte(payload), &recommendations); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	} else {
+		var response structuredResponse
+		if err := json.Unmarshal([]byte(payload), &response); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		recommendations = response.Recommendations
+	}
+
+	if err := ValidateRecommendations(recommendations); err != nil {
+		return nil, err
+	}
+
+	return recommendations, nil
+}
+
+// ValidateRecommendations checks that there is at least one recommendation and that every one
+// has a title and a confidence in [0,1]
+func ValidateRecommendations(recommendations []types.AIRecommendation) error {
+	var problems []string
+	if len(recommendations) == 0 {
+		problems = append(problems, "the recommendations array is empty")
+	}
+	for i, rec := range recommendations {
+		if strings.TrimSpace(rec.Title) == "" {
+			problems = append(problems, fmt.Sprintf("recommendation %d has an empty title", i+1))
+		}
+		if math.IsNaN(rec.Confidence) || rec.Confidence < 0 || rec.Confidence > 1 {
+			problems = append(problems, fmt.Sprintf("recommendation %d has confidence %v outside [0,1]", i+1, rec.Confidence))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid recommendations: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// RepairPrompt asks the model to fix a response that failed to parse or validate
+func RepairPrompt(err error) string {
+	return fmt.Sprintf(`Your previous response could not be used: %v
+
+Respond again with only a JSON object matching the required structure: a "recommendations" array whose items each have a non-empty "title", a "reason", and a "confidence" between 0.0 and 1.0.`, err)
+}
+
+// extractJSON returns the outermost JSON object or array in content, skipping code fences and prose
+func extractJSON(content string) string {
+	start := strings.IndexAny(content, "{[")
+	if start < 0 {
+		return ""
+	}
+
+	closing := "}"
+	if content[start] == '[' {
+		closing = "]"
+	}
+
+	end := strings.LastIndex(content, closing)
+	if end < start {
+		return ""
+	}
+
+	return content[start : end+1]
+}
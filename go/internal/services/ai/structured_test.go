@@ -0,0 +1,42 @@
+package ai
+
+import (
+	"math"
+	"testing"
+
+	"discord-anime-bot/internal/types"
+)
+
+func TestValidateRecommendations(t *testing.T) {
+	tests := []struct {
+		name            string
+		recommendations []types.AIRecommendation
+		wantErr         bool
+	}{
+		{name: "valid", recommendations: []types.AIRecommendation{{Title: "Mushishi", Confidence: 0.8}}},
+		{name: "confidence bounds", recommendations: []types.AIRecommendation{{Title: "A", Confidence: 0}, {Title: "B", Confidence: 1}}},
+		{name: "nil", recommendations: nil, wantErr: true},
+		{name: "empty", recommendations: []types.AIRecommendation{}, wantErr: true},
+		{name: "blank title", recommendations: []types.AIRecommendation{{Title: "  ", Confidence: 0.5}}, wantErr: true},
+		{name: "confidence above 1", recommendations: []types.AIRecommendation{{Title: "A", Confidence: 1.5}}, wantErr: true},
+		{name: "negative confidence", recommendations: []types.AIRecommendation{{Title: "A", Confidence: -0.1}}, wantErr: true},
+		{name: "NaN confidence", recommendations: []types.AIRecommendation{{Title: "A", Confidence: math.NaN()}}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateRecommendations(test.recommendations)
+			if (err != nil) != test.wantErr {
+				t.Errorf("ValidateRecommendations err = %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeRecommendationsRejectsEmptyArray(t *testing.T) {
+	for _, content := range []string{`{"recommendations": []}`, "```json\n[]\n```"} {
+		if _, err := DecodeRecommendations(content); err == nil {
+			t.Errorf("DecodeRecommendations(%q) succeeded, want an error so the repair attempt runs", content)
+		}
+	}
+}
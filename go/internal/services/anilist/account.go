@@ -0,0 +1,229 @@
+package anilist
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"discord-anime-bot/internal/graphql"
+	"discord-anime-bot/internal/services/storage"
+	"discord-anime-bot/internal/types"
+	"discord-anime-bot/internal/utils"
+)
+
+const (
+	defaultAuthorizeURL = "https://anilist.co/api/v2/oauth/authorize"
+	defaultTokenURL     = "https://anilist.co/api/v2/oauth/token"
+
+	// accountKeyPrefix prefixes a Discord user's linked AniList account; it never expires
+	accountKeyPrefix = "anilist:account:"
+	// oauthStateKeyPrefix prefixes a pending link, mapping the OAuth state to the Discord user
+	oauthStateKeyPrefix = "anilist:oauth:state:"
+	// oauthStateTTL is how long an authorization URL from /anime link stays valid
+	oauthStateTTL = 10 * time.Minute
+)
+
+var (
+	// ErrLinkingDisabled is returned when no AniList OAuth app is configured
+	ErrLinkingDisabled = errors.New("AniList account linking is not configured")
+	// ErrNotLinked is returned when a user has no linked AniList account
+	ErrNotLinked = errors.New("no AniList account linked")
+	// ErrInvalidState is returned when an OAuth callback carries an unknown or expired state
+	ErrInvalidState = errors.New("authorization link is invalid or has expired")
+)
+
+// oauthConfig holds the AniList OAuth app used for account linking
+type oauthConfig struct {
+	enabled      bool
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	tokenKey     string
+	authorizeURL string
+	tokenURL     string
+}
+
+// LinkingEnabled reports whether AniList account linking is configured
+func (c *Client) LinkingEnabled() bool {
+	return c.oauth.enabled
+}
+
+// CallbackPath returns the path of the OAuth redirect URL, where the callback handler must be served
+func (c *Client) CallbackPath() string {
+	redirect, err := url.Parse(c.oauth.redirectURL)
+	if err != nil || redirect.Path == "" {
+		return "/"
+	}
+	return redirect.Path
+}
+
+// AuthorizationURL returns the AniList URL a Discord user visits to link their account
+// The embedded state is single-use and expires after oauthStateTTL
+func (c *Client) AuthorizationURL(ctx context.Context, discordUserID string) (string, error) {
+	if !c.oauth.enabled {
+		return "", ErrLinkingDisabled
+	}
+
+	stateBytes := make([]byte, 16)
+	if _, err := rand.Read(stateBytes); err != nil {
+		return "", fmt.Errorf("failed to generate OAuth state: %w", err)
+	}
+	state := hex.EncodeToString(stateBytes)
+
+	if err := c.store.Set(ctx, oauthStateKeyPrefix+state, discordUserID, oauthStateTTL); err != nil {
+		return "", fmt.Errorf("failed to save OAuth state: %w", err)
+	}
+
+	query := url.Values{
+		"client_id":     {c.oauth.clientID},
+		"redirect_uri":  {c.oauth.redirectURL},
+		"response_type": {"code"},
+		"state":         {state},
+	}
+	return c.oauth.authorizeURL + "?" + query.Encode(), nil
+}
+
+// CompleteLink finishes linking for an OAuth callback: it exchanges the code for an access token,
+// looks up the AniList user and stores the encrypted token for the Discord user that started the link
+func (c *Client) CompleteLink(ctx context.Context, state, code string) (string, *types.AniListAccount, error) {
+	if !c.oauth.enabled {
+		return "", nil, ErrLinkingDisabled
+	}
+
+	discordUserID, err := c.store.GetDelete(ctx, oauthStateKeyPrefix+state)
+	if storage.IsNotFound(err) {
+		return "", nil, ErrInvalidState
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	token, err := c.exchangeCode(ctx, code)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var viewer types.ViewerResponse
+	if err := c.queryAs(ctx, token.AccessToken, graphql.GetViewerQuery, map[string]any{}, &viewer); err != nil {
+		return "", nil, fmt.Errorf("failed to get AniList user: %w", err)
+	}
+
+	encrypted, err := utils.Encrypt(c.oauth.tokenKey, []byte(token.AccessToken))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encrypt access token: %w", err)
+	}
+
+	now := time.Now()
+	account := &types.AniListAccount{
+		AniListUserID: viewer.Data.Viewer.ID,
+		Name:          viewer.Data.Viewer.Name,
+		SiteURL:       viewer.Data.Viewer.SiteURL,
+		Token:         encrypted,
+		LinkedAt:      now.Unix(),
+	}
+	if token.ExpiresIn > 0 {
+		account.ExpiresAt = now.Add(time.Duration(token.ExpiresIn) * time.Second).Unix()
+	}
+
+	if err := c.store.Set(ctx, accountKeyPrefix+discordUserID, account, 0); err != nil {
+		return "", nil, fmt.Errorf("failed to save linked account: %w", err)
+	}
+
+	log.Printf("Linked AniList account %s (%d) to Discord user %s", account.Name, account.AniListUserID, discordUserID)
+	return discordUserID, account, nil
+}
+
+// exchangeCode trades an authorization code for an access token
+func (c *Client) exchangeCode(ctx context.Context, code string) (*types.OAuthTokenResponse, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {c.oauth.clientID},
+		"client_secret": {c.oauth.clientSecret},
+		"redirect_uri":  {c.oauth.redirectURL},
+		"code":          {code},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.oauth.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("Error closing response body: %v", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("AniList token exchange failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	var token types.OAuthTokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("AniList token response did not include an access token")
+	}
+	return &token, nil
+}
+
+// GetLinkedAccount returns a Discord user's linked AniList account, or ErrNotLinked
+func (c *Client) GetLinkedAccount(discordUserID string) (*types.AniListAccount, error) {
+	var account types.AniListAccount
+	err := c.store.Get(context.Background(), accountKeyPrefix+discordUserID, &account)
+	if storage.IsNotFound(err) {
+		return nil, ErrNotLinked
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Unlink removes a Discord user's linked AniList account
+func (c *Client) Unlink(discordUserID string) error {
+	return c.store.Delete(context.Background(), accountKeyPrefix+discordUserID)
+}
+
+// accessToken returns the decrypted access token of a linked account
+// Expired tokens are unlinked so the user is asked to link again
+func (c *Client) accessToken(discordUserID string, account *types.AniListAccount) (string, error) {
+	if account.ExpiresAt > 0 && time.Now().Unix() >= account.ExpiresAt {
+		if err := c.Unlink(discordUserID); err != nil {
+			log.Printf("Error unlinking expired AniList account of user %s: %v", discordUserID, err)
+		}
+		return "", ErrNotLinked
+	}
+
+	token, err := utils.Decrypt(c.oauth.tokenKey, account.Token)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt access token: %w", err)
+	}
+	return string(token), nil
+}
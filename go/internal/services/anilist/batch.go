@@ -0,0 +1,73 @@
+package anilist
+
+import (
+	"context"
+	"fmt"
+
+	"discord-anime-bot/internal/graphql"
+	"discord-anime-bot/internal/types"
+)
+
+// batchSize is how many IDs go into a single id_in query (AniList's page size limit)
+const batchSize = 50
+
+// GetAnimeByIDs gets the details of several anime, keyed by ID
+// Cached entries are reused and the rest are fetched with one id_in query per 50 IDs;
+// IDs AniList does not know are missing from the result
+func (c *Client) GetAnimeByIDs(ctx context.Context, animeIDs []int) (map[int]*types.AnimeDetails, error) {
+	return c.getAnimeByIDs(ctx, animeIDs, true)
+}
+
+// RefreshAnimeByIDs is GetAnimeByIDs without reading the cache, for callers that need AniList's current
+// airing times; the fresh details still replace the cached ones
+func (c *Client) RefreshAnimeByIDs(ctx context.Context, animeIDs []int) (map[int]*types.AnimeDetails, error) {
+	return c.getAnimeByIDs(ctx, animeIDs, false)
+}
+
+// getAnimeByIDs fetches anime details in batches, optionally serving cached entries first
+func (c *Client) getAnimeByIDs(ctx context.Context, animeIDs []int, useCache bool) (map[int]*types.AnimeDetails, error) {
+	details := make(map[int]*types.AnimeDetails, len(animeIDs))
+
+	var missing []int
+	for _, animeID := range animeIDs {
+		if _, seen := details[animeID]; seen {
+			continue
+		}
+		if useCache {
+			if cached, ok := c.readMediaCache(ctx, animeID); ok {
+				details[animeID] = cached
+				continue
+			}
+		}
+		details[animeID] = nil
+		missing = append(missing, animeID)
+	}
+
+	for start := 0; start < len(missing); start += batchSize {
+		batch := missing[start:min(start+batchSize, len(missing))]
+
+		variables := types.GraphQLIDsVariables{
+			IDs:     batch,
+			PerPage: batchSize,
+		}
+
+		var result types.AnimeDetailsPageResponse
+		if err := c.query(ctx, graphql.GetAnimeByIDsQuery, variables, &result); err != nil {
+			return nil, fmt.Errorf("failed to fetch %d anime: %w", len(batch), err)
+		}
+
+		for _, media := range result.Data.Page.Media {
+			c.writeMediaCache(ctx, &media)
+			details[media.ID] = &media
+		}
+	}
+
+	// Drop the placeholders of IDs AniList did not return
+	for animeID, anime := range details {
+		if anime == nil {
+			delete(details, animeID)
+		}
+	}
+
+	return details, nil
+}
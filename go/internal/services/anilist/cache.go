@@ -0,0 +1,78 @@
+package anilist
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"discord-anime-bot/internal/services/storage"
+)
+
+const cacheKeyPrefix = "anilist:"
+
+// cacheTTLs holds how long each kind of AniList response is kept in storage
+// A zero TTL disables caching for that kind
+type cacheTTLs struct {
+	media  time.Duration // media details by ID
+	search time.Duration // text and ID search results
+	page   time.Duration // seasonal and releasing pages
+}
+
+// mediaCacheKey returns the cache key for media details
+func mediaCacheKey(animeID int) string {
+	return fmt.Sprintf("%smedia:%d", cacheKeyPrefix, animeID)
+}
+
+// searchCacheKey returns the cache key for a search results page
+func searchCacheKey(query string, page, perPage int) string {
+	return fmt.Sprintf("%ssearch:%d:%d:%s", cacheKeyPrefix, page, perPage, strings.ToLower(strings.TrimSpace(query)))
+}
+
+// releasingCacheKey returns the cache key for a page of releasing anime
+func releasingCacheKey(page, perPage int) string {
+	return fmt.Sprintf("%sreleasing:%d:%d", cacheKeyPrefix, page, perPage)
+}
+
+// seasonCacheKey returns the cache key for a page of seasonal anime
+func seasonCacheKey(season string, year, page, perPage int) string {
+	return fmt.Sprintf("%sseason:%s:%d:%d:%d", cacheKeyPrefix, strings.ToUpper(season), year, page, perPage)
+}
+
+// scheduleCacheKey returns the cache key for a page of an airing schedule window
+func scheduleCacheKey(from, to time.Time, page int) string {
+	return fmt.Sprintf("%sschedule:%d:%d:%d", cacheKeyPrefix, from.Unix(), to.Unix(), page)
+}
+
+// readCache loads a cached response into dest and reports whether it was found
+func (c *Client) readCache(ctx context.Context, key string, dest any) bool {
+	if err := c.store.Get(ctx, key, dest); err != nil {
+		if !storage.IsNotFound(err) {
+			log.Printf("Error reading AniList cache entry %s: %v", key, err)
+		}
+		return false
+	}
+
+	return true
+}
+
+// writeCache stores a response in storage for ttl; non-positive TTLs are not cached
+func (c *Client) writeCache(ctx context.Context, key string, value any, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	if err := c.store.Set(ctx, key, value, ttl); err != nil {
+		log.Printf("Error writing AniList cache entry %s: %v", key, err)
+	}
+}
+
+// airingTTL shortens ttl so the entry expires as soon as an episode airing at airingAt has aired,
+// since AniList's next-episode data is stale from that point on
+func airingTTL(ttl time.Duration, airingAt int) time.Duration {
+	if airingAt <= 0 {
+		return ttl
+	}
+	return min(ttl, time.Until(time.Unix(int64(airingAt), 0)))
+}
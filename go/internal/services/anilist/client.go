@@ -0,0 +1,305 @@
+package anilist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"discord-anime-bot/internal/config"
+	"discord-anime-bot/internal/services/storage"
+	"discord-anime-bot/internal/types"
+)
+
+const (
+	// baseRetryDelay is the first backoff step for failed requests, doubled on every retry
+	baseRetryDelay = 500 * time.Millisecond
+	// maxRetryDelay caps both exponential backoff and server-provided Retry-After values
+	maxRetryDelay = time.Minute
+)
+
+// Client is a reusable AniList GraphQL client with per-request timeouts,
+// retries with backoff and rate-limit handling
+type Client struct {
+	endpoint   string
+	httpClient *http.Client
+	timeout    time.Duration
+	maxRetries int
+	cacheTTL   cacheTTLs
+	oauth      oauthConfig
+	store      storage.Store // response cache, watchlists and linked accounts
+
+	mu           sync.Mutex
+	blockedUntil time.Time // set when AniList reports the rate limit as exhausted
+}
+
+// Option customizes a Client, mainly so tests can point it at a local server
+type Option func(*Client)
+
+// WithHTTPClient makes the client send its requests through httpClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithOAuthEndpoints replaces AniList's OAuth authorize and token URLs
+func WithOAuthEndpoints(authorizeURL, tokenURL string) Option {
+	return func(c *Client) {
+		c.oauth.authorizeURL = authorizeURL
+		c.oauth.tokenURL = tokenURL
+	}
+}
+
+// NewClient creates an AniList client from the bot configuration
+func NewClient(cfg *config.Config, store storage.Store, options ...Option) *Client {
+	client := &Client{
+		store:      store,
+		endpoint:   cfg.AniListAPI,
+		httpClient: &http.Client{},
+		timeout:    cfg.AniListTimeout,
+		maxRetries: cfg.AniListMaxRetries,
+		cacheTTL: cacheTTLs{
+			media:  cfg.AniListMediaCacheTTL,
+			search: cfg.AniListSearchCacheTTL,
+			page:   cfg.AniListPageCacheTTL,
+		},
+		oauth: oauthConfig{
+			enabled:      cfg.IsAniListLinkEnabled,
+			clientID:     cfg.AniListClientID,
+			clientSecret: cfg.AniListClientSecret,
+			redirectURL:  cfg.AniListRedirectURL,
+			tokenKey:     cfg.AniListTokenKey,
+			authorizeURL: defaultAuthorizeURL,
+			tokenURL:     defaultTokenURL,
+		},
+	}
+	for _, option := range options {
+		option(client)
+	}
+	return client
+}
+
+// GraphQLError is a single entry of the errors array returned by AniList
+type GraphQLError struct {
+	Message string `json:"message"`
+	Status  int    `json:"status"`
+}
+
+// APIError is returned when AniList responds with a GraphQL errors array or a non-200 status
+type APIError struct {
+	StatusCode int
+	Errors     []GraphQLError
+}
+
+func (e *APIError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("AniList API request failed with status: %d", e.StatusCode)
+	}
+
+	messages := make([]string, 0, len(e.Errors))
+	for _, gqlErr := range e.Errors {
+		messages = append(messages, gqlErr.Message)
+	}
+	return fmt.Sprintf("AniList API error (status %d): %s", e.StatusCode, strings.Join(messages, "; "))
+}
+
+// IsNotFound reports whether err is an AniList "Not Found" error
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.StatusCode == http.StatusNotFound {
+		return true
+	}
+	for _, gqlErr := range apiErr.Errors {
+		if gqlErr.Status == http.StatusNotFound {
+			return true
+		}
+	}
+	return false
+}
+
+// graphQLEnvelope is used to pull the errors array out of any AniList response
+type graphQLEnvelope struct {
+	Errors []GraphQLError `json:"errors"`
+}
+
+// query sends a GraphQL request to AniList and decodes the full response body into dest
+func (c *Client) query(ctx context.Context, query string, variables any, dest any) error {
+	return c.queryAs(ctx, "", query, variables, dest)
+}
+
+// queryAs sends a GraphQL request on behalf of the user owning accessToken,
+// or anonymously when accessToken is empty
+func (c *Client) queryAs(ctx context.Context, accessToken string, query string, variables any, dest any) error {
+	requestBody := types.GraphQLRequest[any]{
+		Query:     query,
+		Variables: variables,
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if err := c.waitForRateLimit(ctx); err != nil {
+			return err
+		}
+
+		body, retryAfter, err := c.do(ctx, accessToken, jsonData)
+		if err == nil {
+			if err := json.Unmarshal(body, dest); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+			return nil
+		}
+
+		lastErr = err
+		if !isRetryable(err) || attempt == c.maxRetries {
+			break
+		}
+
+		delay := retryAfter
+		if delay <= 0 {
+			delay = backoff(attempt)
+		}
+		log.Printf("AniList request failed (attempt %d/%d), retrying in %v: %v", attempt+1, c.maxRetries+1, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	return lastErr
+}
+
+// do performs a single HTTP round trip and returns the response body,
+// along with any server-requested retry delay when the request failed
+func (c *Client) do(ctx context.Context, accessToken string, jsonData []byte) ([]byte, time.Duration, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("Error closing response body: %v", err)
+		}
+	}()
+
+	retryAfter := c.trackRateLimit(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var envelope graphQLEnvelope
+	_ = json.Unmarshal(body, &envelope)
+
+	if resp.StatusCode != http.StatusOK || len(envelope.Errors) > 0 {
+		return nil, retryAfter, &APIError{StatusCode: resp.StatusCode, Errors: envelope.Errors}
+	}
+
+	return body, 0, nil
+}
+
+// trackRateLimit records AniList's rate-limit headers and returns the delay requested by a 429 response
+func (c *Client) trackRateLimit(resp *http.Response) time.Duration {
+	var blockedUntil time.Time
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			blockedUntil = time.Unix(reset, 0)
+		}
+	}
+
+	var retryAfter time.Duration
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter = time.Minute
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		retryAfter = min(retryAfter, maxRetryDelay)
+		if until := time.Now().Add(retryAfter); until.After(blockedUntil) {
+			blockedUntil = until
+		}
+	}
+
+	if !blockedUntil.IsZero() {
+		c.mu.Lock()
+		if blockedUntil.After(c.blockedUntil) {
+			c.blockedUntil = blockedUntil
+		}
+		c.mu.Unlock()
+	}
+
+	return retryAfter
+}
+
+// waitForRateLimit blocks until AniList's rate-limit window has reset
+func (c *Client) waitForRateLimit(ctx context.Context) error {
+	c.mu.Lock()
+	wait := time.Until(c.blockedUntil)
+	c.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	wait = min(wait, maxRetryDelay)
+
+	log.Printf("AniList rate limit reached, waiting %v", wait)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// isRetryable reports whether a failed request is worth retrying
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	// Network errors and per-attempt timeouts
+	return true
+}
+
+// backoff returns the exponential backoff delay for a retry attempt, with jitter
+func backoff(attempt int) time.Duration {
+	delay := baseRetryDelay << attempt
+	delay += rand.N(delay / 2)
+	return min(delay, maxRetryDelay)
+}
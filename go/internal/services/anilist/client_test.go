@@ -0,0 +1,235 @@
+package anilist
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"discord-anime-bot/internal/config"
+	"discord-anime-bot/internal/services/storage"
+)
+
+// newTestClient returns a client pointed at a test server that answers with handler
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	store, err := storage.NewMemory("", 0)
+	if err != nil {
+		t.Fatalf("NewMemory: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	cfg := &config.Config{
+		AniListAPI:           server.URL,
+		AniListTimeout:       5 * time.Second,
+		AniListMaxRetries:    2,
+		IsAniListLinkEnabled: true,
+		AniListClientID:      "client",
+		AniListClientSecret:  "secret",
+		AniListRedirectURL:   "https://bot.example/anilist/callback",
+		AniListTokenKey:      "token-key",
+	}
+	client := NewClient(cfg, store,
+		WithHTTPClient(server.Client()),
+		WithOAuthEndpoints(server.URL+"/oauth/authorize", server.URL+"/oauth/token"))
+	return client, server
+}
+
+func TestQueryRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"value":42}}`))
+	})
+
+	var result struct {
+		Data struct {
+			Value int `json:"value"`
+		} `json:"data"`
+	}
+	if err := client.query(context.Background(), "query", nil, &result); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if result.Data.Value != 42 {
+		t.Errorf("value = %d, want 42", result.Data.Value)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestQueryGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	err := client.query(context.Background(), "query", nil, &struct{}{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("err = %v, want an APIError with status 500", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("calls = %d, want 3 (one attempt and two retries)", got)
+	}
+}
+
+func TestQueryHonoursRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	})
+
+	start := time.Now()
+	if err := client.query(context.Background(), "query", nil, &struct{}{}); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the 1s Retry-After", elapsed)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestTrackRateLimitBlocksUntilReset(t *testing.T) {
+	reset := time.Now().Add(30 * time.Second).Unix()
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	})
+
+	if err := client.query(context.Background(), "query", nil, &struct{}{}); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if got := client.blockedUntil.Unix(); got != reset {
+		t.Errorf("blockedUntil = %d, want the X-RateLimit-Reset %d", got, reset)
+	}
+
+	// The next request waits for the reset instead of hitting the exhausted limit
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := client.query(ctx, "query", nil, &struct{}{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the request to wait for the rate limit", err)
+	}
+}
+
+func TestQueryMapsGraphQLErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		notFound bool
+		calls    int32
+	}{
+		{
+			name:     "not found in errors array",
+			status:   http.StatusOK,
+			body:     `{"data":null,"errors":[{"message":"Not Found.","status":404}]}`,
+			notFound: true,
+			calls:    1,
+		},
+		{
+			name:     "not found status",
+			status:   http.StatusNotFound,
+			body:     `{"data":null,"errors":[{"message":"Not Found.","status":404}]}`,
+			notFound: true,
+			calls:    1,
+		},
+		{
+			name:   "validation error is not retried",
+			status: http.StatusBadRequest,
+			body:   `{"errors":[{"message":"Variable \"$id\" got invalid value","status":400}]}`,
+			calls:  1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls atomic.Int32
+			client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			})
+
+			err := client.query(context.Background(), "query", nil, &struct{}{})
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want an APIError", err)
+			}
+			if apiErr.StatusCode != test.status || len(apiErr.Errors) != 1 {
+				t.Errorf("APIError = %+v, want status %d and one GraphQL error", apiErr, test.status)
+			}
+			if got := IsNotFound(err); got != test.notFound {
+				t.Errorf("IsNotFound = %v, want %v", got, test.notFound)
+			}
+			if got := calls.Load(); got != test.calls {
+				t.Errorf("calls = %d, want %d", got, test.calls)
+			}
+		})
+	}
+}
+
+func TestCompleteLinkUsesConfiguredEndpoints(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/token":
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != "auth-code" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token":"user-token","token_type":"Bearer","expires_in":3600}`))
+		default:
+			if r.Header.Get("Authorization") != "Bearer user-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"Viewer":{"id":7,"name":"viewer","siteUrl":"https://anilist.co/user/viewer"}}}`))
+		}
+	})
+
+	authorization, err := client.AuthorizationURL(context.Background(), "discord-user")
+	if err != nil {
+		t.Fatalf("AuthorizationURL: %v", err)
+	}
+	if !strings.HasPrefix(authorization, server.URL+"/oauth/authorize?") {
+		t.Fatalf("authorization URL = %s, want the configured authorize endpoint", authorization)
+	}
+	parsed, err := url.Parse(authorization)
+	if err != nil {
+		t.Fatalf("parse authorization URL: %v", err)
+	}
+
+	discordUserID, account, err := client.CompleteLink(context.Background(), parsed.Query().Get("state"), "auth-code")
+	if err != nil {
+		t.Fatalf("CompleteLink: %v", err)
+	}
+	if discordUserID != "discord-user" || account.AniListUserID != 7 {
+		t.Errorf("linked %s to AniList user %d, want discord-user and 7", discordUserID, account.AniListUserID)
+	}
+
+	// The state is single-use
+	if _, _, err := client.CompleteLink(context.Background(), parsed.Query().Get("state"), "auth-code"); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("second CompleteLink err = %v, want ErrInvalidState", err)
+	}
+}
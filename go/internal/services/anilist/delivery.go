@@ -0,0 +1,230 @@
+package anilist
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"discord-anime-bot/internal/types"
+)
+
+// Replicas sharing the same storage each schedule every notification; claims make sure only one of them delivers it
+const (
+	// deliveryClaimKeyPrefix prefixes the claim a replica holds while delivering a batch
+	deliveryClaimKeyPrefix = "notifications:claim:"
+	// noticeClaimKeyPrefix prefixes the claim on a delay notice, which is never released
+	noticeClaimKeyPrefix = "notifications:notice:"
+	// deliveryLeaseTTL is how long a delivery claim lasts; when its holder dies mid-delivery,
+	// another replica takes the batch over once it expires
+	deliveryLeaseTTL = 3 * time.Minute
+	// noticeClaimTTL keeps replicas reconciling the same airing change from each sending the delay notice
+	noticeClaimTTL = 24 * time.Hour
+	// storageSyncInterval is how often notifications created or changed by other replicas are picked up
+	storageSyncInterval = time.Minute
+)
+
+// newInstanceID returns an identifier for this replica that is unique across restarts
+func newInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "bot"
+	}
+	random := make([]byte, 4)
+	_, _ = rand.Read(random)
+	return hostname + "-" + hex.EncodeToString(random)
+}
+
+// claimDelivery claims a batch for delivery by this replica
+// It returns false when another replica holds the claim, and otherwise a function that releases it
+func (ns *NotificationService) claimDelivery(batchKey string) (func(), bool) {
+	return ns.claim(deliveryClaimKeyPrefix + batchKey)
+}
+
+// claim takes a lease on claimKey for deliveryLeaseTTL
+// It returns false when another replica holds the lease, and otherwise a function that releases it
+func (ns *NotificationService) claim(claimKey string) (func(), bool) {
+	ctx := context.Background()
+
+	claimed, err := ns.store.SetNX(ctx, claimKey, ns.instanceID, deliveryLeaseTTL)
+	if err != nil {
+		// Sending twice is better than never sending, so deliver when the claim cannot be checked
+		log.Printf("Error claiming %s, delivering anyway: %v", claimKey, err)
+		return func() {}, true
+	}
+	if !claimed {
+		return nil, false
+	}
+
+	return func() {
+		// Only release our own claim; after a lease expiry it may belong to another replica
+		if _, err := ns.store.DeleteIfValue(ctx, claimKey, ns.instanceID); err != nil {
+			log.Printf("Error releasing delivery claim %s: %v", claimKey, err)
+		}
+	}, true
+}
+
+// claimNotice reports whether this replica should send the delay notice for a batch moving to airingAt
+func (ns *NotificationService) claimNotice(batchKey string, airingAt int64) bool {
+	claimKey := fmt.Sprintf("%s%s:%d", noticeClaimKeyPrefix, batchKey, airingAt)
+	claimed, err := ns.store.SetNX(context.Background(), claimKey, ns.instanceID, noticeClaimTTL)
+	if err != nil {
+		log.Printf("Error claiming delay notice %s, sending anyway: %v", claimKey, err)
+		return true
+	}
+	return claimed
+}
+
+// retryAfterLease checks due notifications claimed by another replica again once that replica's lease expires,
+// so they are still delivered if it died mid-delivery
+func (ns *NotificationService) retryAfterLease(batchKey string, batch *notificationBatch) {
+	log.Printf("Delivery of %s is claimed by another replica, checking again in %v", batchKey, deliveryLeaseTTL)
+
+	// Tracked by ns.wg, so Cleanup neither waits out the lease nor closes the store under a retry
+	ns.wg.Go(func() {
+		select {
+		case <-ns.stop:
+		case <-time.After(deliveryLeaseTTL):
+			ns.deliverDue(batchKey, batch)
+		}
+	})
+}
+
+// syncWithStorage drops the due notifications that another replica already delivered or that were cancelled,
+// following subscriptions that another replica moved on to their next episode
+func (ns *NotificationService) syncWithStorage(entries map[string]*types.NotificationEntry) map[string]*types.NotificationEntry {
+	notificationKeys := make([]string, 0, len(entries))
+	for notificationKey := range entries {
+		notificationKeys = append(notificationKeys, notificationKey)
+	}
+
+	stored, err := ns.readNotifications(context.Background(), notificationKeys)
+	if err != nil {
+		log.Printf("Error checking due notifications against storage, delivering all of them: %v", err)
+		return entries
+	}
+
+	pending := make(map[string]*types.NotificationEntry, len(entries))
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	for notificationKey, entry := range entries {
+		current, exists := stored[notificationKey]
+		if exists && current.Episode == entry.Episode && current.AiringAt == entry.AiringAt {
+			pending[notificationKey] = entry
+			continue
+		}
+
+		if ns.notifications[notificationKey] != entry {
+			continue
+		}
+		ns.unscheduleNotificationInternal(notificationKey)
+		if exists {
+			ns.scheduleNotificationInternal(current)
+		}
+	}
+	return pending
+}
+
+// addToBatch adds an overdue notification to its batch in batches, which have no timers
+// since they are delivered right away
+func addToBatch(batches map[string]*notificationBatch, notificationKey string, entry *types.NotificationEntry) {
+	batchKey := createBatchKey(entry)
+	batch, exists := batches[batchKey]
+	if !exists {
+		batch = &notificationBatch{
+			AnimeID:  entry.AnimeID,
+			Episode:  entry.Episode,
+			AiringAt: entry.AiringAt,
+			Entries:  make(map[string]*types.NotificationEntry),
+		}
+		batches[batchKey] = batch
+	}
+	batch.Entries[notificationKey] = entry
+}
+
+// StartStorageSync begins picking up notifications that other replicas created, changed or removed
+func (ns *NotificationService) StartStorageSync() {
+	ns.wg.Go(func() {
+		ticker := time.NewTicker(storageSyncInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ns.stop:
+				return
+			case <-ticker.C:
+				ns.syncFromStorage()
+			}
+		}
+	})
+}
+
+// syncFromStorage brings the scheduled notifications in line with storage
+// Overdue notifications still in storage were never delivered, for example because the replica
+// holding them died, so they are delivered right away
+func (ns *NotificationService) syncFromStorage() {
+	ctx := context.Background()
+
+	// Snapshot before reading storage: notifications are saved under the lock, so everything
+	// in the snapshot is already in storage unless it was removed there
+	ns.mu.RLock()
+	scheduled := make(map[string]int64, len(ns.notifications))
+	for notificationKey, entry := range ns.notifications {
+		scheduled[notificationKey] = entry.AiringAt
+	}
+	ns.mu.RUnlock()
+
+	indexed, err := ns.store.SortedSetRangeByScoreWithScores(ctx, notificationsByAiringKey, "-inf", "+inf")
+	if err != nil {
+		log.Printf("Error reading notification index: %v", err)
+		return
+	}
+
+	var changed []string
+	inStorage := make(map[string]bool, len(indexed))
+	for _, member := range indexed {
+		inStorage[member.Member] = true
+		if airingAt, exists := scheduled[member.Member]; !exists || airingAt != int64(member.Score) {
+			changed = append(changed, member.Member)
+		}
+	}
+
+	stored, err := ns.readNotifications(ctx, changed)
+	if err != nil {
+		log.Printf("Error reading changed notifications: %v", err)
+		return
+	}
+
+	now := time.Now().Unix()
+	overdue := make(map[string]*notificationBatch) // by batch key, without timers
+	ns.mu.Lock()
+	for notificationKey := range scheduled {
+		if !inStorage[notificationKey] {
+			ns.unscheduleNotificationInternal(notificationKey)
+		}
+	}
+	for notificationKey, entry := range stored {
+		if local, exists := ns.notifications[notificationKey]; exists && *local == *entry {
+			continue
+		}
+		ns.unscheduleNotificationInternal(notificationKey)
+
+		if entry.AiringAt > now {
+			ns.scheduleNotificationInternal(entry)
+			continue
+		}
+		ns.notifications[notificationKey] = entry
+		addToBatch(overdue, notificationKey, entry)
+	}
+	ns.mu.Unlock()
+
+	for batchKey, batch := range overdue {
+		log.Printf("Taking over %d overdue notifications for anime %d episode %d", len(batch.Entries), batch.AnimeID, batch.Episode)
+		ns.wg.Go(func() {
+			ns.deliverDue(batchKey, batch)
+		})
+	}
+}
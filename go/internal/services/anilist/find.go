@@ -1,73 +1,135 @@
 package anilist
 
 import (
-	"encoding/json"
+	"cmp"
+	"context"
 	"fmt"
 	"log"
+	"slices"
+	"sync"
 
-	"discord-anime-bot/internal/config"
-	"discord-anime-bot/internal/services/claude"
-	"discord-anime-bot/internal/services/openai"
+	"discord-anime-bot/internal/services/ai"
 	"discord-anime-bot/internal/types"
+	"discord-anime-bot/internal/utils"
 )
 
-// FindAnimeWithDetails finds anime using AI description and returns AniList details
-func FindAnimeWithDetails(description string, cfg *config.Config) ([]types.AnimeMatch, error) {
-	if !cfg.IsAIEnabled {
-		return nil, fmt.Errorf("AI is not configured. Please set OPENAI_API_KEY or CLAUDE_API_KEY environment variable to use AI-powered anime search")
+const (
+	// findCandidates is how many AniList search results are scored per recommendation
+	findCandidates = 5
+	// verifiedMatchScore is the title similarity at which a candidate counts as a verified match
+	verifiedMatchScore = 0.8
+	// minimumMatchScore is the title similarity below which a recommendation is dropped entirely
+	minimumMatchScore = 0.5
+)
+
+// FindAnimeWithDetails finds anime using AI description and returns AniList details,
+// along with the attribution of the AI provider that answered
+func (c *Client) FindAnimeWithDetails(ctx context.Context, description string, recommender ai.Recommender) ([]types.AnimeMatch, string, error) {
+	if recommender == nil {
+		return nil, "", fmt.Errorf("AI is not configured. Please set OPENAI_API_KEY or CLAUDE_API_KEY environment variable to use AI-powered anime search")
 	}
 
-	var recommendations []types.OpenAIRecommendation
-	var err error
+	result, err := recommender.Recommend(ctx, description)
+	if err != nil {
+		return nil, "", err
+	}
 
-	if cfg.IsOpenAIEnabled {
-		recommendations, err = openai.FindAnimeByDescription(description, cfg.OpenAIAPIKey)
-		if err != nil {
-			return nil, err
-		}
-	} else if cfg.IsClaudeEnabled {
-		claudeClient := claude.NewClaudeClient()
-		if claudeClient == nil {
-			return nil, fmt.Errorf("claude is not configured. Please set CLAUDE_API_KEY environment variable to use AI-powered anime search")
-		}
-		jsonStr, err := claudeClient.FindAnimeByDescription(description)
-		if err != nil {
-			return nil, err
-		}
-		if err := json.Unmarshal([]byte(jsonStr), &recommendations); err != nil {
-			return nil, fmt.Errorf("failed to parse Claude response: %w", err)
-		}
+	// Look up every recommendation on AniList concurrently
+	reconciled := make([]*types.AnimeMatch, len(result.Recommendations))
+	var wg sync.WaitGroup
+	for i, rec := range result.Recommendations {
+		wg.Go(func() {
+			reconciled[i] = c.reconcileRecommendation(ctx, rec)
+		})
 	}
+	wg.Wait()
 
+	// The same show can be recommended under different titles, so keep one match per AniList ID
+	byID := make(map[int]int)
 	var matches []types.AnimeMatch
-
-	// Search for each recommendation on AniList
-	for _, rec := range recommendations {
-		searchResults, err := SearchAnime(rec.Title, 1, 5)
-		if err != nil {
-			log.Printf("Could not search for anime %q: %v", rec.Title, err)
+	for _, match := range reconciled {
+		if match == nil {
 			continue
 		}
 
-		if len(searchResults.Data.Page.Media) > 0 {
-			// Use the first (most relevant) result
-			anime := searchResults.Data.Page.Media[0]
-			matches = append(matches, types.AnimeMatch{
-				Anime:      anime,
-				Reason:     rec.Reason,
-				Confidence: rec.Confidence,
-			})
+		if index, exists := byID[match.Anime.ID]; exists {
+			if betterMatch(*match, matches[index]) {
+				matches[index] = *match
+			}
+			continue
 		}
+
+		byID[match.Anime.ID] = len(matches)
+		matches = append(matches, *match)
 	}
 
-	// Sort by confidence score (higher first)
-	for i := 0; i < len(matches)-1; i++ {
-		for j := i + 1; j < len(matches); j++ {
-			if matches[i].Confidence < matches[j].Confidence {
-				matches[i], matches[j] = matches[j], matches[i]
+	// Verified matches first, then by confidence score (higher first)
+	slices.SortStableFunc(matches, func(a, b types.AnimeMatch) int {
+		if a.Verified != b.Verified {
+			if a.Verified {
+				return -1
 			}
+			return 1
+		}
+		return cmp.Compare(b.Confidence, a.Confidence)
+	})
+
+	return matches, result.Attribution, nil
+}
+
+// reconcileRecommendation scores AniList search candidates against a recommended title
+// and returns the best one, or nil when nothing matches closely enough
+func (c *Client) reconcileRecommendation(ctx context.Context, rec types.AIRecommendation) *types.AnimeMatch {
+	searchResults, err := c.SearchAnime(ctx, rec.Title, 1, findCandidates)
+	if err != nil {
+		log.Printf("Could not search for anime %q: %v", rec.Title, err)
+		return nil
+	}
+
+	var best *types.AnimeMedia
+	bestScore := 0.0
+	for i := range searchResults.Data.Page.Media {
+		candidate := &searchResults.Data.Page.Media[i]
+		// Ties keep AniList's relevance order
+		if score := titleScore(rec.Title, candidate); score > bestScore {
+			best, bestScore = candidate, score
 		}
 	}
 
-	return matches, nil
+	if best == nil || bestScore < minimumMatchScore {
+		log.Printf("Dropping AI recommendation %q: no AniList title matches (best score %.2f)", rec.Title, bestScore)
+		return nil
+	}
+
+	return &types.AnimeMatch{
+		Anime:            *best,
+		Reason:           rec.Reason,
+		Confidence:       rec.Confidence,
+		RecommendedTitle: rec.Title,
+		MatchScore:       bestScore,
+		Verified:         bestScore >= verifiedMatchScore,
+	}
+}
+
+// titleScore returns the best similarity between a title and any of an anime's titles or synonyms
+func titleScore(title string, anime *types.AnimeMedia) float64 {
+	names := []string{anime.Title.Romaji, anime.Title.Native}
+	if anime.Title.English != nil {
+		names = append(names, *anime.Title.English)
+	}
+	names = append(names, anime.Synonyms...)
+
+	best := 0.0
+	for _, name := range names {
+		best = max(best, utils.TitleSimilarity(title, name))
+	}
+	return best
+}
+
+// betterMatch reports whether a should replace b as the match for the same anime
+func betterMatch(a, b types.AnimeMatch) bool {
+	if a.Verified != b.Verified {
+		return a.Verified
+	}
+	return a.Confidence > b.Confidence
 }
@@ -0,0 +1,93 @@
+package anilist
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"discord-anime-bot/internal/types"
+)
+
+// searchClient returns a client whose searches all answer with the given media
+func searchClient(t *testing.T, media ...types.AnimeMedia) *Client {
+	t.Helper()
+	var response types.SearchResponse
+	response.Data.Page.Media = media
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(response)
+	})
+	return client
+}
+
+func english(title string) *string {
+	return &title
+}
+
+func TestReconcileRecommendationThresholds(t *testing.T) {
+	attackOnTitan := types.AnimeMedia{
+		ID:       16498,
+		Title:    types.AnimeTitle{Romaji: "Shingeki no Kyojin", English: english("Attack on Titan"), Native: "進撃の巨人"},
+		Synonyms: []string{"AoT", "SnK"},
+	}
+	heroAcademia := types.AnimeMedia{
+		ID:    21459,
+		Title: types.AnimeTitle{Romaji: "Boku no Hero Academia", English: english("My Hero Academia"), Native: "僕のヒーローアカデミア"},
+	}
+	fullmetal := types.AnimeMedia{
+		ID:    5114,
+		Title: types.AnimeTitle{Romaji: "Hagane no Renkinjutsushi: FULLMETAL ALCHEMIST", English: english("Fullmetal Alchemist: Brotherhood"), Native: "鋼の錬金術師 FULLMETAL ALCHEMIST"},
+	}
+	heroAcademiaRomaji := types.AnimeMedia{
+		ID:    21459,
+		Title: types.AnimeTitle{Romaji: "Boku no Hero Academia", Native: "僕のヒーローアカデミア"},
+	}
+	noEnglish := types.AnimeMedia{
+		ID:    1,
+		Title: types.AnimeTitle{Romaji: "Mushishi", Native: "蟲師"},
+	}
+
+	tests := []struct {
+		name       string
+		title      string
+		candidates []types.AnimeMedia
+		wantID     int
+		verified   bool
+	}{
+		{name: "english title", title: "Attack on Titan", candidates: []types.AnimeMedia{attackOnTitan}, wantID: 16498, verified: true},
+		{name: "romaji title", title: "Shingeki no Kyojin", candidates: []types.AnimeMedia{attackOnTitan}, wantID: 16498, verified: true},
+		{name: "case and punctuation", title: "attack-on-titan!", candidates: []types.AnimeMedia{attackOnTitan}, wantID: 16498, verified: true},
+		{name: "synonym", title: "SnK", candidates: []types.AnimeMedia{attackOnTitan}, wantID: 16498, verified: true},
+		{name: "native title", title: "進撃の巨人", candidates: []types.AnimeMedia{attackOnTitan}, wantID: 16498, verified: true},
+		{name: "missing subtitle", title: "Fullmetal Alchemist", candidates: []types.AnimeMedia{fullmetal}, wantID: 5114, verified: true},
+		{name: "missing english title", title: "Mushishi", candidates: []types.AnimeMedia{noEnglish}, wantID: 1, verified: true},
+		{name: "english title against romaji only is unverified", title: "My Hero Academia", candidates: []types.AnimeMedia{attackOnTitan, heroAcademiaRomaji}, wantID: 21459, verified: false},
+		{name: "best candidate wins", title: "My Hero Academia", candidates: []types.AnimeMedia{attackOnTitan, heroAcademia}, wantID: 21459, verified: true},
+		{name: "unrelated candidates are dropped", title: "Cowboy Bebop", candidates: []types.AnimeMedia{attackOnTitan, heroAcademia}},
+		{name: "no candidates", title: "Cowboy Bebop"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := searchClient(t, test.candidates...)
+			match := client.reconcileRecommendation(context.Background(), types.AIRecommendation{Title: test.title, Confidence: 0.9})
+
+			if test.wantID == 0 {
+				if match != nil {
+					t.Fatalf("matched %d with score %.2f, want the recommendation dropped", match.Anime.ID, match.MatchScore)
+				}
+				return
+			}
+			if match == nil {
+				t.Fatalf("recommendation was dropped, want anime %d", test.wantID)
+			}
+			if match.Anime.ID != test.wantID || match.Verified != test.verified {
+				t.Errorf("matched %d (verified %v, score %.2f), want %d (verified %v)",
+					match.Anime.ID, match.Verified, match.MatchScore, test.wantID, test.verified)
+			}
+			if match.MatchScore < minimumMatchScore || match.Verified != (match.MatchScore >= verifiedMatchScore) {
+				t.Errorf("score %.2f is inconsistent with verified %v", match.MatchScore, match.Verified)
+			}
+		})
+	}
+}
@@ -1,53 +1,58 @@
 package anilist
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"log"
-	"net/http"
-	"os"
+	"context"
+	"time"
 
 	"discord-anime-bot/internal/graphql"
 	"discord-anime-bot/internal/types"
 )
 
 // GetAnimeByID gets anime details by ID including next airing episode
-func GetAnimeByID(animeID int) (*types.AnimeDetails, error) {
-	anilistAPI := os.Getenv("ANILIST_API")
+func (c *Client) GetAnimeByID(ctx context.Context, animeID int) (*types.AnimeDetails, error) {
+	if cached, ok := c.readMediaCache(ctx, animeID); ok {
+		return cached, nil
+	}
 
 	variables := types.GraphQLNextVariables{
 		ID: animeID,
 	}
 
-	requestBody := types.GraphQLRequest[types.GraphQLNextVariables]{
-		Query:     graphql.GetAnimeDetailsQuery,
-		Variables: variables,
+	var result types.AnimeDetailsResponse
+	if err := c.query(ctx, graphql.GetAnimeDetailsQuery, variables, &result); err != nil {
+		return nil, err
 	}
 
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
+	media := result.Data.Media
+	c.writeMediaCache(ctx, &media)
+
+	return &media, nil
+}
 
-	resp, err := http.Post(anilistAPI, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+// readMediaCache returns cached anime details unless they are missing or their next episode has aired
+func (c *Client) readMediaCache(ctx context.Context, animeID int) (*types.AnimeDetails, bool) {
+	var cached types.AnimeDetails
+	if !c.readCache(ctx, mediaCacheKey(animeID), &cached) || hasAired(cached.NextAiringEpisode) {
+		return nil, false
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Error closing response body: %v", err)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+
+	// The countdown was frozen when the entry was cached
+	if cached.NextAiringEpisode != nil {
+		cached.NextAiringEpisode.TimeUntilAiring = int(time.Until(time.Unix(int64(cached.NextAiringEpisode.AiringAt), 0)).Seconds())
 	}
+	return &cached, true
+}
 
-	var result types.AnimeDetailsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+// writeMediaCache caches anime details until their next episode airs at the latest
+func (c *Client) writeMediaCache(ctx context.Context, media *types.AnimeDetails) {
+	ttl := c.cacheTTL.media
+	if media.NextAiringEpisode != nil {
+		ttl = airingTTL(ttl, media.NextAiringEpisode.AiringAt)
 	}
+	c.writeCache(ctx, mediaCacheKey(media.ID), media, ttl)
+}
 
-	return &result.Data.Media, nil
+// hasAired reports whether a cached next episode has already aired, making the cached data stale
+func hasAired(episode *types.NextAiringEpisode) bool {
+	return episode != nil && time.Unix(int64(episode.AiringAt), 0).Before(time.Now())
 }
@@ -0,0 +1,249 @@
+package anilist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"discord-anime-bot/internal/services/storage"
+	"discord-anime-bot/internal/types"
+)
+
+// Notifications are stored as one JSON key each, indexed so they can be found without scanning the keyspace
+const (
+	notificationKeyPrefix = "notification:"
+	// notificationsByAiringKey is a sorted set of notification keys scored by airing time (Unix seconds)
+	notificationsByAiringKey = "notifications:by-airing"
+	// userNotificationsKeyPrefix and animeNotificationsKeyPrefix prefix sets of a user's and an anime's notification keys
+	userNotificationsKeyPrefix  = "notifications:user:"
+	animeNotificationsKeyPrefix = "notifications:anime:"
+	// notificationsIndexedKey marks that notifications saved before the indexes existed have been indexed
+	notificationsIndexedKey = "notifications:indexed"
+	// mgetChunkSize is how many notifications are read per MGET
+	mgetChunkSize = 500
+)
+
+// saveNotification saves a single notification and indexes it in one round trip
+func (ns *NotificationService) saveNotification(notificationKey string, entry *types.NotificationEntry) error {
+	ctx := context.Background()
+
+	persistedEntry := types.PersistedNotification{
+		AnimeID:   entry.AnimeID,
+		ChannelID: entry.ChannelID,
+		GuildID:   entry.GuildID,
+		UserID:    entry.UserID,
+		AiringAt:  entry.AiringAt * 1000, // Convert to milliseconds for consistency
+		Episode:   entry.Episode,
+		Recurring: entry.Recurring,
+		DM:        entry.DM,
+		Auto:      entry.Auto,
+	}
+	data, err := json.Marshal(persistedEntry)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	// Calculate TTL based on airing time, kept for at least the catch-up window so alerts missed
+	// during downtime can still be sent
+	airingTime := time.Unix(entry.AiringAt, 0)
+	ttl := time.Until(airingTime) + max(time.Hour, ns.catchUpWindow)
+	if ttl <= 0 {
+		ttl = time.Minute // Minimum 1 minute TTL
+	}
+	// Subscriptions must survive downtime past an airing time, so they never expire
+	if entry.Recurring {
+		ttl = 0
+	}
+
+	err = ns.store.Pipelined(ctx, func(pipe storage.Pipeline) error {
+		pipe.Set(notificationKeyPrefix+notificationKey, string(data), ttl)
+		indexNotification(pipe, notificationKey, entry)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save notification to storage: %w", err)
+	}
+
+	log.Printf("Saved notification %s to storage", notificationKey)
+	return nil
+}
+
+// deleteNotification removes a notification and its index entries in one round trip
+func (ns *NotificationService) deleteNotification(notificationKey string, entry *types.NotificationEntry) error {
+	ctx := context.Background()
+
+	err := ns.store.Pipelined(ctx, func(pipe storage.Pipeline) error {
+		pipe.Delete(notificationKeyPrefix + notificationKey)
+		unindexNotification(pipe, notificationKey, entry.AnimeID, entry.UserID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove notification from storage: %w", err)
+	}
+
+	log.Printf("Removed notification %s from storage", notificationKey)
+	return nil
+}
+
+// indexNotification queues the index updates for a saved notification
+func indexNotification(pipe storage.Pipeline, notificationKey string, entry *types.NotificationEntry) {
+	pipe.SortedSetAdd(notificationsByAiringKey, float64(entry.AiringAt), notificationKey)
+	pipe.SetAdd(userNotificationsKeyPrefix+entry.UserID, notificationKey)
+	pipe.SetAdd(animeNotificationsKeyPrefix+strconv.Itoa(entry.AnimeID), notificationKey)
+}
+
+// unindexNotification queues the removal of a notification from every index
+func unindexNotification(pipe storage.Pipeline, notificationKey string, animeID int, userID string) {
+	pipe.SortedSetRemove(notificationsByAiringKey, notificationKey)
+	pipe.SetRemove(userNotificationsKeyPrefix+userID, notificationKey)
+	pipe.SetRemove(animeNotificationsKeyPrefix+strconv.Itoa(animeID), notificationKey)
+}
+
+// loadPersistedNotifications reads every indexed notification, keyed by notification key
+func (ns *NotificationService) loadPersistedNotifications(ctx context.Context) (map[string]*types.NotificationEntry, error) {
+	if err := ns.ensureNotificationIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	notificationKeys, err := ns.store.SortedSetRangeByScore(ctx, notificationsByAiringKey, "-inf", "+inf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification index: %w", err)
+	}
+	return ns.readNotifications(ctx, notificationKeys)
+}
+
+// readNotifications reads notifications with chunked MGETs
+// Keys whose notification has expired are dropped from the indexes
+func (ns *NotificationService) readNotifications(ctx context.Context, notificationKeys []string) (map[string]*types.NotificationEntry, error) {
+	entries := make(map[string]*types.NotificationEntry, len(notificationKeys))
+	var expired []string
+
+	for start := 0; start < len(notificationKeys); start += mgetChunkSize {
+		chunk := notificationKeys[start:min(start+mgetChunkSize, len(notificationKeys))]
+
+		storageKeys := make([]string, len(chunk))
+		for index, notificationKey := range chunk {
+			storageKeys[index] = notificationKeyPrefix + notificationKey
+		}
+
+		values, found, err := ns.store.MGet(ctx, storageKeys...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read notifications: %w", err)
+		}
+
+		for index, notificationKey := range chunk {
+			if !found[index] {
+				expired = append(expired, notificationKey)
+				continue
+			}
+
+			var persisted types.PersistedNotification
+			if err := json.Unmarshal([]byte(values[index]), &persisted); err != nil {
+				log.Printf("Skipping invalid notification %s: %v", notificationKey, err)
+				continue
+			}
+			persisted.AiringAt /= 1000 // Convert to seconds
+			entries[notificationKey] = &persisted
+		}
+	}
+
+	if len(expired) > 0 {
+		ns.removeExpiredFromIndex(ctx, expired)
+	}
+	return entries, nil
+}
+
+// removeExpiredFromIndex drops index entries whose notification key has expired
+func (ns *NotificationService) removeExpiredFromIndex(ctx context.Context, notificationKeys []string) {
+	err := ns.store.Pipelined(ctx, func(pipe storage.Pipeline) error {
+		for _, notificationKey := range notificationKeys {
+			animeID, userID, ok := parseNotificationKey(notificationKey)
+			if !ok {
+				pipe.SortedSetRemove(notificationsByAiringKey, notificationKey)
+				continue
+			}
+			unindexNotification(pipe, notificationKey, animeID, userID)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error removing %d expired notifications from the index: %v", len(notificationKeys), err)
+	}
+}
+
+// ensureNotificationIndex indexes notifications saved before the indexes existed
+// It runs a single SCAN the first time and is a no-op afterwards
+func (ns *NotificationService) ensureNotificationIndex(ctx context.Context) error {
+	indexed, err := ns.store.Exists(ctx, notificationsIndexedKey)
+	if err != nil {
+		return fmt.Errorf("failed to check notification index: %w", err)
+	}
+	if indexed {
+		return nil
+	}
+
+	storageKeys, err := ns.store.Keys(ctx, notificationKeyPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to scan notifications: %w", err)
+	}
+
+	notificationKeys := make([]string, len(storageKeys))
+	for index, storageKey := range storageKeys {
+		notificationKeys[index] = strings.TrimPrefix(storageKey, notificationKeyPrefix)
+	}
+
+	entries, err := ns.readNotifications(ctx, notificationKeys)
+	if err != nil {
+		return err
+	}
+
+	err = ns.store.Pipelined(ctx, func(pipe storage.Pipeline) error {
+		for notificationKey, entry := range entries {
+			indexNotification(pipe, notificationKey, entry)
+		}
+		pipe.Set(notificationsIndexedKey, strconv.FormatInt(time.Now().Unix(), 10), 0)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index notifications: %w", err)
+	}
+
+	log.Printf("Indexed %d existing notifications", len(entries))
+	return nil
+}
+
+// readIndexedNotifications reads the notifications listed in an index set
+func (ns *NotificationService) readIndexedNotifications(ctx context.Context, indexKey string) ([]*types.NotificationEntry, error) {
+	notificationKeys, err := ns.store.SetMembers(ctx, indexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := ns.readNotifications(ctx, notificationKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make([]*types.NotificationEntry, 0, len(entries))
+	for _, entry := range entries {
+		notifications = append(notifications, entry)
+	}
+	return notifications, nil
+}
+
+// parseNotificationKey extracts the anime and user from a notification key ("anime-channel-user")
+func parseNotificationKey(notificationKey string) (int, string, bool) {
+	parts := strings.Split(notificationKey, "-")
+	if len(parts) < 3 {
+		return 0, "", false
+	}
+	animeID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", false
+	}
+	return animeID, parts[len(parts)-1], true
+}
@@ -2,20 +2,28 @@ package anilist
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"log"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
-	"discord-anime-bot/internal/services/redis"
+	"discord-anime-bot/internal/config"
+	"discord-anime-bot/internal/services/settings"
+	"discord-anime-bot/internal/services/storage"
 	"discord-anime-bot/internal/types"
+	"discord-anime-bot/internal/utils"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 // GetNextEpisode gets the next airing episode for an anime
-func GetNextEpisode(animeID int) (*types.NextAiringEpisode, error) {
-	anime, err := GetAnimeByID(animeID)
+func (c *Client) GetNextEpisode(ctx context.Context, animeID int) (*types.NextAiringEpisode, error) {
+	anime, err := c.GetAnimeByID(ctx, animeID)
 	if err != nil {
 		return nil, err
 	}
@@ -23,25 +31,77 @@ func GetNextEpisode(animeID int) (*types.NextAiringEpisode, error) {
 	return anime.NextAiringEpisode, nil
 }
 
-// notificationTimer holds a notification with its timer
-type notificationTimer struct {
-	Entry      *types.NotificationEntry
+// Counters of alerts missed while the bot was down, published with expvar
+var (
+	caughtUpAlerts  = expvar.NewInt("notifications_caught_up")
+	discardedAlerts = expvar.NewInt("notifications_missed_discarded")
+)
+
+// lateAlertThreshold is how late an alert must go out to say when the episode aired instead of "now airing"
+const lateAlertThreshold = 5 * time.Minute
+
+// subscriptionRecheckInterval is how long a subscription waits before asking AniList
+// again when the next episode has no airing date
+const subscriptionRecheckInterval = 6 * time.Hour
+
+// Discord limits for a single alert message
+const (
+	// maxMentionsPerMessage keeps each message well under Discord's 100 allowed user mentions
+	maxMentionsPerMessage = 50
+	// maxContentLength is Discord's message content limit
+	maxContentLength = 2000
+)
+
+// notificationBatch groups the notifications for one episode of an anime that are delivered to the same place,
+// so they share a single timer, AniList lookup and message
+type notificationBatch struct {
+	AnimeID    int
+	Episode    int
+	AiringAt   int64
+	Entries    map[string]*types.NotificationEntry // by notification key
 	Timer      *time.Timer
 	CancelFunc context.CancelFunc
 }
 
 // NotificationService handles episode notifications
 type NotificationService struct {
-	notifications map[string]*notificationTimer
+	notifications map[string]*types.NotificationEntry // by notification key (anime, channel, user)
+	batches       map[string]*notificationBatch       // by batch key (anime, episode, delivery channel)
 	session       *discordgo.Session
+	anilist       *Client
+	store         storage.Store
+	guilds        *settings.Service
 	mu            sync.RWMutex
+
+	// Reconciler that keeps pending notifications in line with AniList's airing times
+	reconcileInterval time.Duration
+	delayNotices      bool
+	stop              chan struct{}
+	wg                sync.WaitGroup
+
+	// How long after airing an alert missed while the bot was down is still sent on startup,
+	// and the missed batches loaded from storage until StartCatchUp sends them
+	catchUpWindow time.Duration
+	missed        map[string]*notificationBatch
+
+	// Identifies this replica in delivery claims
+	instanceID string
 }
 
 // NewNotificationService creates a new notification service
-func NewNotificationService(session *discordgo.Session) *NotificationService {
+func NewNotificationService(session *discordgo.Session, client *Client, store storage.Store, guilds *settings.Service, cfg *config.Config) *NotificationService {
 	service := &NotificationService{
-		notifications: make(map[string]*notificationTimer),
-		session:       session,
+		notifications:     make(map[string]*types.NotificationEntry),
+		batches:           make(map[string]*notificationBatch),
+		session:           session,
+		anilist:           client,
+		store:             store,
+		guilds:            guilds,
+		reconcileInterval: cfg.NotificationReconcileInterval,
+		delayNotices:      cfg.NotificationDelayNotices,
+		stop:              make(chan struct{}),
+		catchUpWindow:     cfg.NotificationCatchUpWindow,
+		instanceID:        newInstanceID(),
 	}
 
 	// Load existing notifications
@@ -50,56 +110,75 @@ func NewNotificationService(session *discordgo.Session) *NotificationService {
 	return service
 }
 
-// loadNotifications loads notifications from Redis and reschedules them
+// loadNotifications loads notifications from storage and reschedules them
 func (ns *NotificationService) loadNotifications() {
 	ctx := context.Background()
 
-	// Get all notification keys from Redis
-	notificationKeys, err := redis.Keys(ctx, "notification:*")
+	// Read every indexed notification with pipelined MGETs
+	notifications, err := ns.loadPersistedNotifications(ctx)
 	if err != nil {
-		log.Printf("Error fetching notification keys from Redis: %v", err)
+		log.Printf("Error loading notifications from storage: %v", err)
 		return
 	}
 
-	if len(notificationKeys) == 0 {
-		log.Println("No notifications found in Redis")
+	if len(notifications) == 0 {
+		log.Println("No notifications found in storage")
 		return
 	}
 
 	now := time.Now()
 	loadedCount := 0
-	var mu sync.Mutex // Protect the loadedCount variable
+	missed := make(map[string]*notificationBatch) // by batch key, without timers
+	var mu sync.Mutex                             // Protect loadedCount and missed
 	var wg sync.WaitGroup
 
 	// Process notifications concurrently using wg.Go()
-	for _, redisKey := range notificationKeys {
+	for notificationKey, notification := range notifications {
 		wg.Go(func() {
-			var persistedNotification types.PersistedNotification
-			err := redis.Get(ctx, redisKey, &persistedNotification)
-			if err != nil {
-				log.Printf("Error getting notification %s from Redis: %v", redisKey, err)
-				return
-			}
-
 			// Skip expired notifications
-			airingTime := time.Unix(persistedNotification.AiringAt/1000, 0)
+			airingTime := time.Unix(notification.AiringAt, 0)
 			if airingTime.Before(now) {
+				// Alerts that aired while the bot was down are still sent within the catch-up window
+				if notification.Episode > 0 && now.Sub(airingTime) <= ns.catchUpWindow {
+					ns.mu.Lock()
+					ns.notifications[notificationKey] = notification
+					ns.mu.Unlock()
+					mu.Lock()
+					addToBatch(missed, notificationKey, notification)
+					mu.Unlock()
+					return
+				}
+				if notification.Episode > 0 {
+					ns.discardMissedAlert(notification, now.Sub(airingTime))
+				}
+
+				// Subscriptions outlive a single episode, so move them on to the next one
+				if notification.Recurring {
+					next := ns.nextSubscriptionEntry(notification, ns.refreshAnime(notification.AnimeID))
+					if next != nil {
+						ns.mu.Lock()
+						ns.scheduleNotificationInternal(next)
+						ns.mu.Unlock()
+						if err := ns.saveNotification(notificationKey, next); err != nil {
+							log.Printf("Error saving subscription %s to storage: %v", notificationKey, err)
+						}
+						mu.Lock()
+						loadedCount++
+						mu.Unlock()
+						return
+					}
+				}
+
 				// Remove expired notification
-				if err := redis.Delete(ctx, redisKey); err != nil {
-					log.Printf("Error deleting expired notification %s from Redis: %v", redisKey, err)
+				if err := ns.deleteNotification(notificationKey, notification); err != nil {
+					log.Printf("Error deleting expired notification %s from storage: %v", notificationKey, err)
 				}
 				return
 			}
 
-			notification := types.NotificationEntry{
-				AnimeID:   persistedNotification.AnimeID,
-				ChannelID: persistedNotification.ChannelID,
-				UserID:    persistedNotification.UserID,
-				AiringAt:  persistedNotification.AiringAt / 1000, // Convert to seconds
-				Episode:   persistedNotification.Episode,
-			}
-
-			ns.scheduleNotificationInternal(&notification)
+			ns.mu.Lock()
+			ns.scheduleNotificationInternal(notification)
+			ns.mu.Unlock()
 
 			mu.Lock()
 			loadedCount++
@@ -110,50 +189,43 @@ func (ns *NotificationService) loadNotifications() {
 	// Wait for all goroutines to complete
 	wg.Wait()
 
-	log.Printf("Loaded %d active notifications from Redis", loadedCount)
-}
+	log.Printf("Loaded %d active notifications from storage", loadedCount)
 
-// saveNotificationToRedis saves a single notification to Redis
-func (ns *NotificationService) saveNotificationToRedis(notificationKey string, entry *types.NotificationEntry) error {
-	ctx := context.Background()
-	redisKey := "notification:" + notificationKey
+	// Missed alerts are only sent once the session is open and the delivery queue runs
+	ns.mu.Lock()
+	ns.missed = missed
+	ns.mu.Unlock()
+}
 
-	persistedEntry := types.PersistedNotification{
-		AnimeID:   entry.AnimeID,
-		ChannelID: entry.ChannelID,
-		UserID:    entry.UserID,
-		AiringAt:  entry.AiringAt * 1000, // Convert to milliseconds for consistency
-		Episode:   entry.Episode,
-	}
+// StartCatchUp delivers the alerts that aired while the bot was down, as loaded on startup
+// Call it after the Discord session is open and StartDeliveryQueue has run
+func (ns *NotificationService) StartCatchUp() {
+	ns.mu.Lock()
+	missed := ns.missed
+	ns.missed = nil
+	ns.mu.Unlock()
 
-	// Calculate TTL based on airing time (with buffer)
-	airingTime := time.Unix(entry.AiringAt, 0)
-	ttl := time.Until(airingTime) + time.Hour // 1 hour buffer
-	if ttl <= 0 {
-		ttl = time.Minute // Minimum 1 minute TTL
+	for batchKey, batch := range missed {
+		log.Printf("Catching up on %d alerts for anime %d episode %d that aired while the bot was down",
+			len(batch.Entries), batch.AnimeID, batch.Episode)
+		caughtUpAlerts.Add(int64(len(batch.Entries)))
+		// Tracked by ns.wg, so Cleanup waits for catch-up deliveries still in flight
+		ns.wg.Go(func() {
+			ns.deliverDue(batchKey, batch)
+		})
 	}
 
-	err := redis.Set(ctx, redisKey, persistedEntry, ttl)
-	if err != nil {
-		return fmt.Errorf("failed to save notification to Redis: %w", err)
+	// Report the counters in the log too, since the metrics server can be disabled
+	if caught, discarded := caughtUpAlerts.Value(), discardedAlerts.Value(); caught > 0 || discarded > 0 {
+		log.Printf("Catch-up after downtime: %d missed alerts being delivered late, %d discarded", caught, discarded)
 	}
-
-	log.Printf("Saved notification %s to Redis", notificationKey)
-	return nil
 }
 
-// removeNotificationFromRedis removes a notification from Redis
-func (ns *NotificationService) removeNotificationFromRedis(notificationKey string) error {
-	ctx := context.Background()
-	redisKey := "notification:" + notificationKey
-
-	err := redis.Delete(ctx, redisKey)
-	if err != nil {
-		return fmt.Errorf("failed to remove notification from Redis: %w", err)
-	}
-
-	log.Printf("Removed notification %s from Redis", notificationKey)
-	return nil
+// discardMissedAlert reports an alert that aired too long before startup to still be sent
+func (ns *NotificationService) discardMissedAlert(entry *types.NotificationEntry, late time.Duration) {
+	log.Printf("Discarding missed alert for anime %d episode %d for user %s: aired %v ago, outside the %v catch-up window",
+		entry.AnimeID, entry.Episode, entry.UserID, late.Round(time.Minute), ns.catchUpWindow)
+	discardedAlerts.Add(1)
 }
 
 // createNotificationKey creates a unique key for a notification
@@ -161,7 +233,18 @@ func createNotificationKey(animeID int, channelID, userID string) string {
 	return fmt.Sprintf("%d-%s-%s", animeID, channelID, userID)
 }
 
-// scheduleNotificationInternal sets up a timer for the notification (without locking)
+// createBatchKey creates the key of the batch a notification is delivered with
+// DM notifications each go to their own user, so they are batched per user
+func createBatchKey(entry *types.NotificationEntry) string {
+	destination := entry.ChannelID
+	if entry.DM {
+		destination = "dm:" + entry.UserID
+	}
+	return fmt.Sprintf("%d-%d-%s", entry.AnimeID, entry.Episode, destination)
+}
+
+// scheduleNotificationInternal adds a notification to the batch for its episode and destination,
+// creating the batch timer when needed (without locking)
 func (ns *NotificationService) scheduleNotificationInternal(entry *types.NotificationEntry) {
 	notificationKey := createNotificationKey(entry.AnimeID, entry.ChannelID, entry.UserID)
 
@@ -173,104 +256,380 @@ func (ns *NotificationService) scheduleNotificationInternal(entry *types.Notific
 		return
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ns.notifications[notificationKey] = entry
+
+	batchKey := createBatchKey(entry)
+	batch, exists := ns.batches[batchKey]
+	if exists {
+		batch.Entries[notificationKey] = entry
+		if batch.AiringAt == entry.AiringAt {
+			return
+		}
 
-	timer := time.AfterFunc(delay, func() {
+		// The newest entry carries the latest airing time from AniList, so move the whole batch to it
+		batch.Timer.Stop()
+		batch.CancelFunc()
+		batch.AiringAt = entry.AiringAt
+		for key, member := range batch.Entries {
+			if member == entry {
+				continue
+			}
+			member.AiringAt = entry.AiringAt
+			if err := ns.saveNotification(key, member); err != nil {
+				log.Printf("Error saving rescheduled notification %s to storage: %v", key, err)
+			}
+		}
+	} else {
+		batch = &notificationBatch{
+			AnimeID:  entry.AnimeID,
+			Episode:  entry.Episode,
+			AiringAt: entry.AiringAt,
+			Entries:  map[string]*types.NotificationEntry{notificationKey: entry},
+		}
+		ns.batches[batchKey] = batch
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	batch.CancelFunc = cancel
+	batch.Timer = time.AfterFunc(delay, func() {
 		select {
 		case <-ctx.Done():
 			return
 		default:
-			ns.sendNotification(entry)
-			// Remove the notification after sending
-			ns.mu.Lock()
-			delete(ns.notifications, notificationKey)
-			ns.mu.Unlock()
-			// Remove from Redis as well
-			if err := ns.removeNotificationFromRedis(notificationKey); err != nil {
-				log.Printf("Error removing notification from Redis: %v", err)
-			}
+			ns.handleDueBatch(batchKey, batch)
 		}
 	})
 
-	ns.notifications[notificationKey] = &notificationTimer{
-		Entry:      entry,
-		Timer:      timer,
-		CancelFunc: cancel,
+	log.Printf("Scheduled notification for anime %d in %v", entry.AnimeID, delay)
+}
+
+// unscheduleNotificationInternal removes a notification from the service and its batch,
+// stopping the batch timer once nobody is left in it (without locking)
+func (ns *NotificationService) unscheduleNotificationInternal(notificationKey string) {
+	entry, exists := ns.notifications[notificationKey]
+	if !exists {
+		return
 	}
+	delete(ns.notifications, notificationKey)
 
-	log.Printf("Scheduled notification for anime %d in %v", entry.AnimeID, delay)
+	batchKey := createBatchKey(entry)
+	batch, exists := ns.batches[batchKey]
+	if !exists || batch.Entries[notificationKey] != entry {
+		return
+	}
+
+	delete(batch.Entries, notificationKey)
+	if len(batch.Entries) == 0 {
+		batch.Timer.Stop()
+		batch.CancelFunc()
+		delete(ns.batches, batchKey)
+	}
 }
 
-// sendNotification sends the Discord notification
-func (ns *NotificationService) sendNotification(entry *types.NotificationEntry) {
-	// Get anime details for the notification
-	anime, err := GetAnimeByID(entry.AnimeID)
-	if err != nil {
-		log.Printf("Error getting anime details for notification: %v", err)
+// handleDueBatch takes a due batch off the schedule and delivers it
+func (ns *NotificationService) handleDueBatch(batchKey string, batch *notificationBatch) {
+	ns.mu.Lock()
+	if ns.batches[batchKey] != batch {
+		ns.mu.Unlock()
+		return
+	}
+	// Once off the schedule nothing else touches the batch's entries
+	delete(ns.batches, batchKey)
+	ns.mu.Unlock()
+
+	ns.deliverDue(batchKey, batch)
+}
+
+// deliverDue queues the alert of a due batch with a single AniList lookup and then retires each notification or,
+// for subscriptions, schedules the following episode
+// Only the replica holding the batch's delivery claim queues it; the others check again once the claim expires
+func (ns *NotificationService) deliverDue(batchKey string, batch *notificationBatch) {
+	release, claimed := ns.claimDelivery(batchKey)
+	if !claimed {
+		ns.retryAfterLease(batchKey, batch)
+		return
+	}
+	defer release()
+
+	// Another replica may have delivered the batch before we got the claim
+	entries := ns.syncWithStorage(batch.Entries)
+	if len(entries) == 0 {
 		return
 	}
 
-	title := anime.Title.Romaji
-	if anime.Title.English != nil && *anime.Title.English != "" {
-		title = *anime.Title.English
+	anime := ns.refreshAnime(batch.AnimeID)
+
+	// Subscriptions waiting on an airing date fire only to re-check AniList; alerts go through
+	// the delivery queue, which retries them until Discord accepts them
+	if batch.Episode > 0 {
+		ns.enqueueAlert(batchKey, batch.AnimeID, batch.Episode, batch.AiringAt, anime, slices.Collect(maps.Values(entries)))
+	}
+
+	for notificationKey, entry := range entries {
+		var next *types.NotificationEntry
+		if entry.Recurring {
+			next = ns.nextSubscriptionEntry(entry, anime)
+		}
+
+		ns.mu.Lock()
+		// The notification may have been cancelled or replaced while we were sending
+		if ns.notifications[notificationKey] != entry {
+			ns.mu.Unlock()
+			continue
+		}
+
+		delete(ns.notifications, notificationKey)
+		if next != nil {
+			ns.scheduleNotificationInternal(next)
+			if err := ns.saveNotification(notificationKey, next); err != nil {
+				log.Printf("Error saving subscription to storage: %v", err)
+			}
+		} else if err := ns.deleteNotification(notificationKey, entry); err != nil {
+			log.Printf("Error removing notification from storage: %v", err)
+		}
+		ns.mu.Unlock()
+	}
+}
+
+// refreshAnime fetches the current details of an anime, returning nil when AniList could not be reached
+func (ns *NotificationService) refreshAnime(animeID int) *types.AnimeDetails {
+	anime, err := ns.anilist.GetAnimeByID(context.Background(), animeID)
+	if err != nil {
+		log.Printf("Error getting anime details for %d: %v", animeID, err)
+		return nil
+	}
+	return anime
+}
+
+// nextSubscriptionEntry returns the entry that follows a subscription's current episode,
+// or nil once the anime has finished or been cancelled
+// A nil anime means AniList could not be reached, so the subscription re-checks later
+func (ns *NotificationService) nextSubscriptionEntry(entry *types.NotificationEntry, anime *types.AnimeDetails) *types.NotificationEntry {
+	next := *entry
+	recheckAt := time.Now().Add(subscriptionRecheckInterval).Unix()
+
+	if anime == nil {
+		log.Printf("Could not refresh subscription for anime %d, retrying in %v", entry.AnimeID, subscriptionRecheckInterval)
+		next.Episode = 0
+		next.AiringAt = recheckAt
+		return &next
+	}
+
+	if anime.Status == "FINISHED" || anime.Status == "CANCELLED" {
+		log.Printf("Anime %d is %s, retiring subscription for user %s", entry.AnimeID, anime.Status, entry.UserID)
+		return nil
+	}
+
+	if anime.NextAiringEpisode != nil && int64(anime.NextAiringEpisode.AiringAt) > time.Now().Unix() {
+		next.Episode = anime.NextAiringEpisode.Episode
+		next.AiringAt = int64(anime.NextAiringEpisode.AiringAt)
+		return &next
+	}
+
+	// Still airing but the next episode has not been scheduled yet
+	next.Episode = 0
+	next.AiringAt = recheckAt
+	return &next
+}
+
+// alertEmbed builds the episode alert for a batch in the guild's preferred title language
+// Alerts sent well after airingAt, such as those caught up after downtime, say when the episode aired
+func alertEmbed(guild *settings.Settings, title types.AnimeTitle, coverImage string, episode int, airingAt int64, entries []types.NotificationEntry) *discordgo.MessageEmbed {
+	description := fmt.Sprintf("**Episode %d** of **%s** is now airing!", episode, guild.Title(title))
+	if airedAt := time.Unix(airingAt, 0); airingAt > 0 && time.Since(airedAt) >= lateAlertThreshold {
+		description = fmt.Sprintf("**Episode %d** of **%s** aired %s!", episode, guild.Title(title), utils.FormatRelativeTimestamp(airedAt))
 	}
 
 	embed := &discordgo.MessageEmbed{
 		Title:       "Episode Alert!",
-		Description: fmt.Sprintf("**Episode %d** of **%s** is now airing!", entry.Episode, title),
+		Description: description,
 		Color:       0x00FF00,
 		Thumbnail: &discordgo.MessageEmbedThumbnail{
-			URL: anime.CoverImage.Large,
+			URL: coverImage,
 		},
 		Footer: &discordgo.MessageEmbedFooter{
 			Text: "Set more notifications with /anime notify add",
 		},
 		Timestamp: time.Now().Format(time.RFC3339),
 	}
+	if !slices.ContainsFunc(entries, func(entry types.NotificationEntry) bool { return !entry.Auto }) {
+		embed.Footer.Text = "From your watchlist · Stop with /anime notify action:auto-off"
+	}
+	return embed
+}
 
-	content := fmt.Sprintf("<@%s>", entry.UserID)
+// postToSubscribers posts an embed to a batch's destination, mentioning every subscriber
+// and optionally the guild's ping role; it reports whether every message was sent
+func (ns *NotificationService) postToSubscribers(entries []*types.NotificationEntry, guild *settings.Settings, embed *discordgo.MessageEmbed, pingRole bool) bool {
+	channelID, rolePing, err := ns.resolveDestination(entries[0], guild, pingRole)
+	if err != nil {
+		log.Printf("Error opening DM channel for user %s: %v", entries[0].UserID, err)
+		return false
+	}
 
-	_, err = ns.session.ChannelMessageSendComplex(entry.ChannelID, &discordgo.MessageSend{
-		Content: content,
-		Embeds:  []*discordgo.MessageEmbed{embed},
-	})
+	userIDs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		userIDs = append(userIDs, entry.UserID)
+	}
+	slices.Sort(userIDs)
 
-	if err != nil {
+	if _, err := ns.sendMessages(channelID, embed, mentionMessages(userIDs, rolePing), 0); err != nil {
 		log.Printf("Error sending notification: %v", err)
-	} else {
-		log.Printf("Sent notification for anime %s episode %d to user %s", title, entry.Episode, entry.UserID)
+		return false
 	}
+	return true
 }
 
-// AddNotification adds a new episode notification
-func (ns *NotificationService) AddNotification(animeID int, channelID, userID string, airingAt time.Time, episode int) error {
-	notificationKey := createNotificationKey(animeID, channelID, userID)
+// resolveDestination returns the channel a batch is posted to and the role ping to prefix it with
+// Guild batches go to the guild's notification channel when one is set, DM batches to the subscriber's DMs
+func (ns *NotificationService) resolveDestination(first *types.NotificationEntry, guild *settings.Settings, pingRole bool) (string, string, error) {
+	if first.DM {
+		channel, err := ns.session.UserChannelCreate(first.UserID)
+		if err != nil {
+			return "", "", err
+		}
+		return channel.ID, "", nil
+	}
 
-	ns.mu.Lock()
-	defer ns.mu.Unlock()
+	channelID := first.ChannelID
+	if guild.NotificationChannelID != "" {
+		channelID = guild.NotificationChannelID
+	}
+	var rolePing string
+	if pingRole && guild.PingRoleID != "" {
+		rolePing = fmt.Sprintf("<@&%s>", guild.PingRoleID)
+	}
+	return channelID, rolePing, nil
+}
 
-	// Check if notification already exists
-	if existing, exists := ns.notifications[notificationKey]; exists {
-		existing.Timer.Stop()
-		existing.CancelFunc()
-		delete(ns.notifications, notificationKey)
+// sendMessages sends the mention messages of an alert starting at index from, attaching the embed to the first one
+// It returns how many of the messages have been sent, counting the ones skipped
+func (ns *NotificationService) sendMessages(channelID string, embed *discordgo.MessageEmbed, contents []string, from int) (int, error) {
+	sent := from
+	for index := from; index < len(contents); index++ {
+		message := &discordgo.MessageSend{Content: contents[index]}
+		if index == 0 {
+			message.Embeds = []*discordgo.MessageEmbed{embed}
+		}
+		if _, err := ns.session.ChannelMessageSendComplex(channelID, message); err != nil {
+			return sent, err
+		}
+		sent++
 	}
+	return sent, nil
+}
+
+// mentionMessages splits user mentions over as many message contents as needed to respect Discord's limits
+// The role ping, if any, goes at the start of the first message
+func mentionMessages(userIDs []string, rolePing string) []string {
+	var messages []string
+	var current strings.Builder
+	count := 0
 
+	if rolePing != "" {
+		current.WriteString(rolePing)
+	}
+	for _, userID := range userIDs {
+		mention := fmt.Sprintf("<@%s>", userID)
+		if count == maxMentionsPerMessage || current.Len()+len(mention)+1 > maxContentLength {
+			messages = append(messages, current.String())
+			current.Reset()
+			count = 0
+		}
+		if current.Len() > 0 {
+			current.WriteString(" ")
+		}
+		current.WriteString(mention)
+		count++
+	}
+	if current.Len() > 0 {
+		messages = append(messages, current.String())
+	}
+	return messages
+}
+
+// AddNotification adds a new episode notification
+// When dm is set the alert is sent to the user's direct messages instead of channelID
+func (ns *NotificationService) AddNotification(animeID int, guildID, channelID, userID string, airingAt time.Time, episode int, dm bool) error {
 	entry := &types.NotificationEntry{
 		AnimeID:   animeID,
 		ChannelID: channelID,
+		GuildID:   guildID,
 		UserID:    userID,
 		AiringAt:  airingAt.Unix(),
 		Episode:   episode,
+		DM:        dm,
 	}
 
 	log.Printf("Adding notification for anime %d, episode %d", animeID, episode)
 
+	return ns.addEntry(entry)
+}
+
+// AddSubscription subscribes a user to every upcoming episode of an anime
+// nextEpisode may be nil when AniList has not announced the next airing date yet
+func (ns *NotificationService) AddSubscription(animeID int, guildID, channelID, userID string, nextEpisode *types.NextAiringEpisode, dm bool) error {
+	entry := newSubscriptionEntry(animeID, guildID, channelID, userID, nextEpisode, dm)
+
+	log.Printf("Adding subscription for anime %d, next episode %d", animeID, entry.Episode)
+
+	return ns.addEntry(entry)
+}
+
+// AddAutoSubscription subscribes a user to an anime on behalf of their watchlist auto-notify
+func (ns *NotificationService) AddAutoSubscription(animeID int, guildID, channelID, userID string, nextEpisode *types.NextAiringEpisode, dm bool) error {
+	entry := newSubscriptionEntry(animeID, guildID, channelID, userID, nextEpisode, dm)
+	entry.Auto = true
+
+	log.Printf("Adding watchlist auto-notify subscription for anime %d, user %s", animeID, userID)
+
+	return ns.addEntry(entry)
+}
+
+// newSubscriptionEntry builds a recurring entry for the next episode, or a recheck when none is scheduled
+func newSubscriptionEntry(animeID int, guildID, channelID, userID string, nextEpisode *types.NextAiringEpisode, dm bool) *types.NotificationEntry {
+	entry := &types.NotificationEntry{
+		AnimeID:   animeID,
+		ChannelID: channelID,
+		GuildID:   guildID,
+		UserID:    userID,
+		Recurring: true,
+		DM:        dm,
+	}
+
+	if nextEpisode != nil {
+		entry.Episode = nextEpisode.Episode
+		entry.AiringAt = int64(nextEpisode.AiringAt)
+	} else {
+		entry.AiringAt = time.Now().Add(subscriptionRecheckInterval).Unix()
+	}
+	return entry
+}
+
+// HasNotification reports whether a notification exists for an anime, channel and user
+func (ns *NotificationService) HasNotification(animeID int, channelID, userID string) bool {
+	ns.mu.RLock()
+	defer ns.mu.RUnlock()
+
+	_, exists := ns.notifications[createNotificationKey(animeID, channelID, userID)]
+	return exists
+}
+
+// addEntry schedules and persists a notification, replacing any existing one with the same key
+func (ns *NotificationService) addEntry(entry *types.NotificationEntry) error {
+	notificationKey := createNotificationKey(entry.AnimeID, entry.ChannelID, entry.UserID)
+
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	// Replace any existing notification with the same key
+	ns.unscheduleNotificationInternal(notificationKey)
+
 	// Schedule the notification (internal method that doesn't acquire locks)
 	ns.scheduleNotificationInternal(entry)
 
-	// Save to Redis
-	return ns.saveNotificationToRedis(notificationKey, entry)
+	// Save to storage
+	return ns.saveNotification(notificationKey, entry)
 }
 
 // RemoveNotification removes a notification for a specific anime and user
@@ -280,28 +639,53 @@ func (ns *NotificationService) RemoveNotification(animeID int, channelID, userID
 	ns.mu.Lock()
 	defer ns.mu.Unlock()
 
-	timer, exists := ns.notifications[notificationKey]
+	entry, exists := ns.notifications[notificationKey]
 	if !exists {
 		return fmt.Errorf("notification not found for anime %d and user %s", animeID, userID)
 	}
 
-	timer.Timer.Stop()
-	timer.CancelFunc()
-	delete(ns.notifications, notificationKey)
+	ns.unscheduleNotificationInternal(notificationKey)
 
-	// Remove from Redis
-	return ns.removeNotificationFromRedis(notificationKey)
+	// Remove from storage
+	return ns.deleteNotification(notificationKey, entry)
 }
 
 // GetUserNotifications returns all notifications for a specific user
+// They are read from the per-user index in storage, so they reflect every replica's changes
 func (ns *NotificationService) GetUserNotifications(userID string) []*types.NotificationEntry {
+	notifications, err := ns.readIndexedNotifications(context.Background(), userNotificationsKeyPrefix+userID)
+	if err == nil {
+		return notifications
+	}
+	log.Printf("Error reading notifications of user %s from storage, using scheduled notifications: %v", userID, err)
+
+	return ns.scheduledNotifications(func(entry *types.NotificationEntry) bool {
+		return entry.UserID == userID
+	})
+}
+
+// GetAnimeNotifications returns all notifications for a specific anime
+func (ns *NotificationService) GetAnimeNotifications(animeID int) []*types.NotificationEntry {
+	notifications, err := ns.readIndexedNotifications(context.Background(), animeNotificationsKeyPrefix+strconv.Itoa(animeID))
+	if err == nil {
+		return notifications
+	}
+	log.Printf("Error reading notifications of anime %d from storage, using scheduled notifications: %v", animeID, err)
+
+	return ns.scheduledNotifications(func(entry *types.NotificationEntry) bool {
+		return entry.AnimeID == animeID
+	})
+}
+
+// scheduledNotifications returns the scheduled notifications that match a filter
+func (ns *NotificationService) scheduledNotifications(match func(*types.NotificationEntry) bool) []*types.NotificationEntry {
 	ns.mu.RLock()
 	defer ns.mu.RUnlock()
 
 	var notifications []*types.NotificationEntry
-	for _, timer := range ns.notifications {
-		if timer.Entry.UserID == userID {
-			notifications = append(notifications, timer.Entry)
+	for _, entry := range ns.notifications {
+		if match(entry) {
+			notifications = append(notifications, entry)
 		}
 	}
 
@@ -311,21 +695,29 @@ func (ns *NotificationService) GetUserNotifications(userID string) []*types.Noti
 // Cleanup stops all timers
 func (ns *NotificationService) Cleanup() {
 	ns.mu.Lock()
-	timers := make([]*notificationTimer, 0, len(ns.notifications))
-	for _, timer := range ns.notifications {
-		timers = append(timers, timer)
+	batches := make([]*notificationBatch, 0, len(ns.batches))
+	for _, batch := range ns.batches {
+		batches = append(batches, batch)
 	}
 	ns.mu.Unlock()
 
 	log.Println("Cleaning up notification service...")
 
+	// Stop the reconciler before tearing down the timers it may reschedule
+	select {
+	case <-ns.stop:
+	default:
+		close(ns.stop)
+	}
+	ns.wg.Wait()
+
 	var wg sync.WaitGroup
 
 	// Clean up timers concurrently using the new wg.Go() pattern (Go 1.25+)
-	for _, timer := range timers {
+	for _, batch := range batches {
 		wg.Go(func() {
-			timer.Timer.Stop()
-			timer.CancelFunc()
+			batch.Timer.Stop()
+			batch.CancelFunc()
 		})
 	}
 
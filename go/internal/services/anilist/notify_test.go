@@ -0,0 +1,85 @@
+package anilist
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// userIDs returns n distinct user IDs of the given length
+func userIDs(n, length int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		id := fmt.Sprintf("%d", i+1)
+		ids[i] = strings.Repeat("9", length-len(id)) + id
+	}
+	return ids
+}
+
+func TestMentionMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		userIDs  []string
+		rolePing string
+		messages int
+	}{
+		{name: "no mentions", messages: 0},
+		{name: "role ping only", rolePing: "<@&42>", messages: 1},
+		{name: "one mention", userIDs: userIDs(1, 18), messages: 1},
+		{name: "exactly 50 mentions", userIDs: userIDs(50, 18), messages: 1},
+		{name: "51 mentions", userIDs: userIDs(51, 18), messages: 2},
+		{name: "51 mentions with role ping", userIDs: userIDs(51, 18), rolePing: "<@&42>", messages: 2},
+		{name: "100 mentions", userIDs: userIDs(100, 18), messages: 2},
+		{name: "101 mentions", userIDs: userIDs(101, 18), messages: 3},
+		// 50 mentions of 63 characters plus spaces are 3199 characters, so the length limit splits first
+		{name: "long IDs exceed the length limit", userIDs: userIDs(50, 60), messages: 2},
+		{name: "long IDs with role ping", userIDs: userIDs(31, 60), rolePing: "<@&123456789012345678>", messages: 2},
+		{name: "long IDs just fit", userIDs: userIDs(31, 60), messages: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			messages := mentionMessages(test.userIDs, test.rolePing)
+			if len(messages) != test.messages {
+				t.Fatalf("got %d messages, want %d: %q", len(messages), test.messages, messages)
+			}
+
+			var mentioned []string
+			for i, message := range messages {
+				if len(message) > maxContentLength {
+					t.Errorf("message %d is %d characters, over the %d limit", i, len(message), maxContentLength)
+				}
+				if message == "" {
+					t.Errorf("message %d is empty", i)
+				}
+
+				fields := strings.Fields(message)
+				if test.rolePing != "" {
+					if i == 0 && (len(fields) == 0 || fields[0] != test.rolePing) {
+						t.Errorf("first message %q does not start with the role ping", message)
+					}
+					if i > 0 && strings.Contains(message, test.rolePing) {
+						t.Errorf("message %d repeats the role ping", i)
+					}
+				}
+
+				users := 0
+				for _, field := range fields {
+					if field == test.rolePing {
+						continue
+					}
+					mentioned = append(mentioned, strings.TrimSuffix(strings.TrimPrefix(field, "<@"), ">"))
+					users++
+				}
+				if users > maxMentionsPerMessage {
+					t.Errorf("message %d mentions %d users, over the %d limit", i, users, maxMentionsPerMessage)
+				}
+			}
+
+			// Every user is mentioned exactly once, in order
+			if strings.Join(mentioned, ",") != strings.Join(test.userIDs, ",") {
+				t.Errorf("mentioned %v, want %v", mentioned, test.userIDs)
+			}
+		})
+	}
+}
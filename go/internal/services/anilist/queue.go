@@ -0,0 +1,323 @@
+package anilist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"discord-anime-bot/internal/services/storage"
+	"discord-anime-bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Episode alerts go through a delivery queue in storage, so an alert Discord rejects is retried with backoff
+// instead of being lost, and one that keeps failing is kept in a dead-letter list for the guild's admins
+const (
+	// deliveryJobKeyPrefix prefixes a queued alert
+	deliveryJobKeyPrefix = "deliveries:job:"
+	// deliveriesPendingKey is a sorted set of queued alert IDs scored by their next attempt time in seconds
+	deliveriesPendingKey = "deliveries:pending"
+	// deliveryLockKeyPrefix prefixes the lease a replica holds while attempting an alert
+	deliveryLockKeyPrefix = "deliveries:lock:"
+	// deadDeliveriesKeyPrefix prefixes a guild's list of alerts that could not be delivered, newest first
+	deadDeliveriesKeyPrefix = "deliveries:dead:"
+	// deadDeliveriesDMKey holds the failed alerts that were going to DMs, which no guild admin should see
+	deadDeliveriesDMKey = deadDeliveriesKeyPrefix + "dm"
+	// maxDeadDeliveries is how many failed alerts are kept per guild
+	maxDeadDeliveries = 50
+
+	// maxDeliveryAttempts is how often an alert is tried before it is given up on
+	maxDeliveryAttempts = 8
+	// deliveryRetryBase is the wait before the first retry; it doubles after every failed attempt
+	deliveryRetryBase = 30 * time.Second
+	// deliveryRetryMax caps the wait between two attempts
+	deliveryRetryMax = time.Hour
+	// deliveryJobTTL removes queued alerts that were orphaned, well after their last retry would have run
+	deliveryJobTTL = 48 * time.Hour
+	// deliveryQueueInterval is how often the queue is checked for alerts due a retry
+	deliveryQueueInterval = 15 * time.Second
+)
+
+// enqueueAlert queues the alert for an episode and makes the first attempt right away
+// anime may be nil when AniList could not be reached; its details are then looked up on each attempt
+func (ns *NotificationService) enqueueAlert(batchKey string, animeID, episode int, airingAt int64, anime *types.AnimeDetails, entries []*types.NotificationEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	job := &types.Delivery{
+		AnimeID:       animeID,
+		Episode:       episode,
+		AiringAt:      airingAt,
+		Entries:       make([]types.NotificationEntry, 0, len(entries)),
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	for _, entry := range entries {
+		job.Entries = append(job.Entries, *entry)
+	}
+	job.ID = deliveryID(batchKey, job.Entries)
+	if anime != nil {
+		job.Title = &anime.Title
+		job.CoverImage = anime.CoverImage.Large
+	}
+
+	// A replica that took over a batch after a crash queues the same ID, so the alert is not queued twice
+	queued, err := ns.store.SetNX(ctx, deliveryJobKeyPrefix+job.ID, job, deliveryJobTTL)
+	if err != nil {
+		log.Printf("Error queueing alert %s, sending it directly: %v", job.ID, err)
+		if err := ns.sendDelivery(job); err != nil {
+			log.Printf("Error sending alert %s: %v", job.ID, err)
+		}
+		return
+	}
+	if !queued {
+		log.Printf("Alert %s is already queued", job.ID)
+		return
+	}
+	if err := ns.store.SortedSetAdd(ctx, deliveriesPendingKey, float64(job.NextAttemptAt), job.ID); err != nil {
+		log.Printf("Error adding alert %s to the delivery queue: %v", job.ID, err)
+	}
+
+	ns.attemptDelivery(job.ID)
+}
+
+// deliveryID identifies the alert of a batch by its batch key and subscribers
+func deliveryID(batchKey string, entries []types.NotificationEntry) string {
+	userIDs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		userIDs = append(userIDs, entry.UserID)
+	}
+	slices.Sort(userIDs)
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(strings.Join(userIDs, ",")))
+	return fmt.Sprintf("%s-%08x", batchKey, hash.Sum32())
+}
+
+// attemptDelivery tries to send a queued alert once
+// On failure the alert is retried with exponential backoff, or moved to the dead-letter list once
+// the error is permanent or it ran out of attempts
+func (ns *NotificationService) attemptDelivery(id string) {
+	release, claimed := ns.claim(deliveryLockKeyPrefix + id)
+	if !claimed {
+		return
+	}
+	defer release()
+
+	ctx := context.Background()
+	var job types.Delivery
+	err := ns.store.Get(ctx, deliveryJobKeyPrefix+id, &job)
+	if storage.IsNotFound(err) {
+		// Sent or given up on by another replica
+		if err := ns.store.SortedSetRemove(ctx, deliveriesPendingKey, id); err != nil {
+			log.Printf("Error removing alert %s from the delivery queue: %v", id, err)
+		}
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading queued alert %s: %v", id, err)
+		return
+	}
+	// Another replica already failed this attempt and scheduled the next one
+	if job.NextAttemptAt > time.Now().Unix() {
+		return
+	}
+
+	err = ns.sendDelivery(&job)
+	if err == nil {
+		ns.removeDelivery(&job)
+		return
+	}
+
+	job.Attempts++
+	job.LastError = deliveryErrorMessage(err)
+	job.Permanent = isPermanentDeliveryError(err)
+	if job.Permanent || job.Attempts >= maxDeliveryAttempts {
+		log.Printf("Giving up on alert %s after %d attempts: %v", id, job.Attempts, err)
+		ns.deadLetter(&job)
+		return
+	}
+
+	delay := min(deliveryRetryBase<<(job.Attempts-1), deliveryRetryMax)
+	job.NextAttemptAt = time.Now().Add(delay).Unix()
+	log.Printf("Error delivering alert %s (attempt %d), retrying in %v: %v", id, job.Attempts, delay, err)
+
+	err = ns.store.Pipelined(ctx, func(pipe storage.Pipeline) error {
+		pipe.Set(deliveryJobKeyPrefix+id, &job, deliveryJobTTL)
+		pipe.SortedSetAdd(deliveriesPendingKey, float64(job.NextAttemptAt), id)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error rescheduling alert %s: %v", id, err)
+	}
+}
+
+// sendDelivery sends the messages of a queued alert that have not gone out yet, recording its progress in job
+func (ns *NotificationService) sendDelivery(job *types.Delivery) error {
+	if job.Title == nil {
+		anime, err := ns.anilist.GetAnimeByID(context.Background(), job.AnimeID)
+		if err != nil {
+			return fmt.Errorf("failed to get anime details: %w", err)
+		}
+		job.Title = &anime.Title
+		job.CoverImage = anime.CoverImage.Large
+	}
+
+	first := &job.Entries[0]
+	guild, err := ns.guilds.Get(context.Background(), first.GuildID)
+	if err != nil {
+		log.Printf("Error loading guild settings for notification, using defaults: %v", err)
+	}
+
+	channelID, rolePing, err := ns.resolveDestination(first, guild, true)
+	if err != nil {
+		return fmt.Errorf("failed to open DM channel: %w", err)
+	}
+	job.ChannelID = channelID
+
+	userIDs := make([]string, 0, len(job.Entries))
+	for _, entry := range job.Entries {
+		userIDs = append(userIDs, entry.UserID)
+	}
+	slices.Sort(userIDs)
+
+	embed := alertEmbed(guild, *job.Title, job.CoverImage, job.Episode, job.AiringAt, job.Entries)
+	job.Sent, err = ns.sendMessages(channelID, embed, mentionMessages(userIDs, rolePing), job.Sent)
+	if err != nil {
+		return fmt.Errorf("failed to send alert: %w", err)
+	}
+
+	log.Printf("Sent notification for anime %s episode %d to %d users", guild.Title(*job.Title), job.Episode, len(job.Entries))
+	return nil
+}
+
+// removeDelivery takes an alert off the queue
+func (ns *NotificationService) removeDelivery(job *types.Delivery) {
+	err := ns.store.Pipelined(context.Background(), func(pipe storage.Pipeline) error {
+		pipe.Delete(deliveryJobKeyPrefix + job.ID)
+		pipe.SortedSetRemove(deliveriesPendingKey, job.ID)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error removing alert %s from the delivery queue: %v", job.ID, err)
+	}
+}
+
+// deadLetter takes a failed alert off the queue and records it in the dead-letter list of its guild
+func (ns *NotificationService) deadLetter(job *types.Delivery) {
+	ctx := context.Background()
+	job.FailedAt = time.Now().Unix()
+
+	key := deadDeliveriesKey(&job.Entries[0])
+	if err := ns.store.ListPush(ctx, key, job); err != nil {
+		log.Printf("Error recording failed alert %s: %v", job.ID, err)
+	} else if err := ns.store.ListTrim(ctx, key, 0, maxDeadDeliveries-1); err != nil {
+		log.Printf("Error trimming failed alerts %s: %v", key, err)
+	}
+
+	ns.removeDelivery(job)
+}
+
+// deadDeliveriesKey returns the dead-letter list an alert for entry's destination goes to
+func deadDeliveriesKey(entry *types.NotificationEntry) string {
+	if entry.DM || entry.GuildID == "" {
+		return deadDeliveriesDMKey
+	}
+	return deadDeliveriesKeyPrefix + entry.GuildID
+}
+
+// FailedDeliveries returns the most recent alerts of a guild that could not be delivered, newest first
+func (ns *NotificationService) FailedDeliveries(ctx context.Context, guildID string) ([]types.Delivery, error) {
+	values, err := ns.store.ListRange(ctx, deadDeliveriesKeyPrefix+guildID, 0, -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load failed deliveries of guild %s: %w", guildID, err)
+	}
+
+	deliveries := make([]types.Delivery, 0, len(values))
+	for _, value := range values {
+		var delivery types.Delivery
+		if err := json.Unmarshal([]byte(value), &delivery); err != nil {
+			log.Printf("Error decoding failed delivery of guild %s: %v", guildID, err)
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+// isPermanentDeliveryError reports whether retrying an alert cannot help, such as when the bot
+// lacks permissions, the channel was deleted or the user does not accept DMs
+func isPermanentDeliveryError(err error) bool {
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) {
+		if restErr.Message != nil {
+			switch restErr.Message.Code {
+			case discordgo.ErrCodeUnknownChannel, discordgo.ErrCodeUnknownGuild, discordgo.ErrCodeUnknownUser,
+				discordgo.ErrCodeMissingAccess, discordgo.ErrCodeCannotSendMessagesToThisUser, discordgo.ErrCodeMissingPermissions:
+				return true
+			}
+		}
+		return restErr.Response != nil &&
+			(restErr.Response.StatusCode == http.StatusForbidden || restErr.Response.StatusCode == http.StatusNotFound)
+	}
+	// The anime was removed from AniList
+	return IsNotFound(err)
+}
+
+// deliveryErrorMessage describes why an alert failed, preferring Discord's own message over the raw response
+func deliveryErrorMessage(err error) string {
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Message != nil && restErr.Message.Message != "" {
+		return fmt.Sprintf("%s (Discord error %d)", restErr.Message.Message, restErr.Message.Code)
+	}
+	return err.Error()
+}
+
+// StartDeliveryQueue begins retrying queued alerts, starting with the ones left over from before a restart
+func (ns *NotificationService) StartDeliveryQueue() {
+	ns.wg.Go(func() {
+		ticker := time.NewTicker(deliveryQueueInterval)
+		defer ticker.Stop()
+
+		for {
+			ns.processDeliveryQueue()
+
+			select {
+			case <-ns.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// processDeliveryQueue attempts every queued alert that is due
+func (ns *NotificationService) processDeliveryQueue() {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	ids, err := ns.store.SortedSetRangeByScore(context.Background(), deliveriesPendingKey, "-inf", now)
+	if err != nil {
+		log.Printf("Error reading the delivery queue: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		select {
+		case <-ns.stop:
+			return
+		default:
+			ns.attemptDelivery(id)
+		}
+	}
+}
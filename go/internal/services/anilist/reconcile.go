@@ -0,0 +1,160 @@
+package anilist
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"time"
+
+	"discord-anime-bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// delayNoticeThreshold is the smallest airing time change subscribers are told about
+const delayNoticeThreshold = 10 * time.Minute
+
+// StartReconciler begins checking pending notifications against AniList's airing times in the background
+func (ns *NotificationService) StartReconciler() {
+	ns.wg.Go(func() {
+		ticker := time.NewTicker(ns.reconcileInterval)
+		defer ticker.Stop()
+
+		for {
+			ns.reconcile()
+			select {
+			case <-ns.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// reconcile re-fetches the next episode of every anime with pending notifications, bypassing the cache,
+// and moves the batches whose episode or airing time changed
+func (ns *NotificationService) reconcile() {
+	ns.mu.RLock()
+	batches := make(map[string]*notificationBatch, len(ns.batches))
+	var animeIDs []int
+	for batchKey, batch := range ns.batches {
+		batches[batchKey] = batch
+		if !slices.Contains(animeIDs, batch.AnimeID) {
+			animeIDs = append(animeIDs, batch.AnimeID)
+		}
+	}
+	ns.mu.RUnlock()
+
+	if len(animeIDs) == 0 {
+		return
+	}
+
+	details, err := ns.anilist.RefreshAnimeByIDs(context.Background(), animeIDs)
+	if err != nil {
+		log.Printf("Error refreshing airing times for %d anime: %v", len(animeIDs), err)
+		return
+	}
+
+	for batchKey, batch := range batches {
+		anime := details[batch.AnimeID]
+		// Without a next episode AniList has nothing newer to offer, so keep the current schedule
+		if anime == nil || anime.NextAiringEpisode == nil {
+			continue
+		}
+		ns.reconcileBatch(batchKey, batch, anime)
+	}
+}
+
+// reconcileBatch moves a batch to the anime's current next episode when its episode or airing time changed
+// If the episode the batch waits for already aired, the batch is delivered right away
+func (ns *NotificationService) reconcileBatch(batchKey string, batch *notificationBatch, anime *types.AnimeDetails) {
+	next := anime.NextAiringEpisode
+	nextAiringAt := int64(next.AiringAt)
+
+	ns.mu.Lock()
+	if ns.batches[batchKey] != batch || (batch.Episode == next.Episode && batch.AiringAt == nextAiringAt) {
+		ns.mu.Unlock()
+		return
+	}
+
+	// AniList has moved past our episode, so it aired earlier than we thought
+	if batch.Episode > 0 && next.Episode > batch.Episode {
+		batch.Timer.Stop()
+		batch.CancelFunc()
+		ns.mu.Unlock()
+
+		log.Printf("Episode %d of anime %d aired earlier than scheduled, delivering now", batch.Episode, batch.AnimeID)
+		ns.wg.Go(func() {
+			ns.handleDueBatch(batchKey, batch)
+		})
+		return
+	}
+
+	// The batch timer is about to fire anyway
+	if nextAiringAt <= time.Now().Unix() {
+		ns.mu.Unlock()
+		return
+	}
+
+	previousEpisode, previousAiringAt := batch.Episode, batch.AiringAt
+	// Take every entry out first; the moved entries may land in a batch with the same key
+	moved := make(map[string]*types.NotificationEntry, len(batch.Entries))
+	for notificationKey, entry := range batch.Entries {
+		updated := *entry
+		updated.Episode = next.Episode
+		updated.AiringAt = nextAiringAt
+		moved[notificationKey] = &updated
+	}
+	for notificationKey := range moved {
+		ns.unscheduleNotificationInternal(notificationKey)
+	}
+	for notificationKey, entry := range moved {
+		ns.scheduleNotificationInternal(entry)
+		if err := ns.saveNotification(notificationKey, entry); err != nil {
+			log.Printf("Error saving rescheduled notification %s to storage: %v", notificationKey, err)
+		}
+	}
+	ns.mu.Unlock()
+
+	log.Printf("Rescheduled %d notifications for anime %d from episode %d at %s to episode %d at %s",
+		len(moved), batch.AnimeID, previousEpisode, time.Unix(previousAiringAt, 0).Format(time.RFC3339),
+		next.Episode, time.Unix(nextAiringAt, 0).Format(time.RFC3339))
+
+	// Only a shifted airing time of the same episode is news to subscribers; waiting subscriptions
+	// simply picked up their first airing date
+	shift := time.Duration(nextAiringAt-previousAiringAt) * time.Second
+	if ns.delayNotices && previousEpisode > 0 && previousEpisode == next.Episode && shift.Abs() >= delayNoticeThreshold &&
+		ns.claimNotice(batchKey, nextAiringAt) {
+		ns.sendDelayNotice(anime, next.Episode, previousAiringAt, nextAiringAt, slices.Collect(maps.Values(moved)))
+	}
+}
+
+// sendDelayNotice tells a batch's subscribers that their episode's airing time changed
+func (ns *NotificationService) sendDelayNotice(anime *types.AnimeDetails, episode int, previousAiringAt, airingAt int64, entries []*types.NotificationEntry) {
+	guild, err := ns.guilds.Get(context.Background(), entries[0].GuildID)
+	if err != nil {
+		log.Printf("Error loading guild settings for delay notice, using defaults: %v", err)
+	}
+
+	title := "Episode Delayed"
+	if airingAt < previousAiringAt {
+		title = "Episode Moved Earlier"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: title,
+		Description: fmt.Sprintf("**Episode %d** of **%s** now airs <t:%d:F> (<t:%d:R>) instead of <t:%d:F>. Your alert has been moved to the new time.",
+			episode, guild.Title(anime.Title), airingAt, airingAt, previousAiringAt),
+		Color: 0xFFA500,
+		Thumbnail: &discordgo.MessageEmbedThumbnail{
+			URL: anime.CoverImage.Large,
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	if ns.postToSubscribers(entries, guild, embed, false) {
+		log.Printf("Sent delay notice for anime %d episode %d to %d users", anime.ID, episode, len(entries))
+	}
+}
@@ -1,54 +1,49 @@
 package anilist
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"log"
-	"net/http"
-	"os"
+	"context"
 
 	"discord-anime-bot/internal/graphql"
 	"discord-anime-bot/internal/types"
 )
 
 // GetReleasingAnime gets all currently releasing anime
-func GetReleasingAnime(page, perPage int) (*types.ReleasingAnimeResponse, error) {
-	anilistAPI := os.Getenv("ANILIST_API")
+func (c *Client) GetReleasingAnime(ctx context.Context, page, perPage int) (*types.ReleasingAnimeResponse, error) {
+	cacheKey := releasingCacheKey(page, perPage)
+
+	var cached types.ReleasingAnimeResponse
+	if c.readCache(ctx, cacheKey, &cached) && !anyAired(cached.Data.Page.Media) {
+		return &cached, nil
+	}
 
 	variables := types.GraphQLSearchVariables{
 		Page:    page,
 		PerPage: perPage,
 	}
 
-	requestBody := types.GraphQLRequest[types.GraphQLSearchVariables]{
-		Query:     graphql.GetReleasingAnimeQuery,
-		Variables: variables,
-	}
-
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	var result types.ReleasingAnimeResponse
+	if err := c.query(ctx, graphql.GetReleasingAnimeQuery, variables, &result); err != nil {
+		return nil, err
 	}
 
-	resp, err := http.Post(anilistAPI, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Error closing response body: %v", err)
+	// Expire the page as soon as any listed episode airs
+	ttl := c.cacheTTL.page
+	for _, anime := range result.Data.Page.Media {
+		if anime.NextAiringEpisode != nil {
+			ttl = airingTTL(ttl, anime.NextAiringEpisode.AiringAt)
 		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
-	}
-
-	var result types.ReleasingAnimeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
+	c.writeCache(ctx, cacheKey, result, ttl)
 
 	return &result, nil
 }
+
+// anyAired reports whether any next episode in a cached page has already aired
+func anyAired(media []types.ReleasingAnime) bool {
+	for _, anime := range media {
+		if hasAired(anime.NextAiringEpisode) {
+			return true
+		}
+	}
+	return false
+}
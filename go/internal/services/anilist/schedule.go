@@ -0,0 +1,68 @@
+package anilist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"discord-anime-bot/internal/graphql"
+	"discord-anime-bot/internal/types"
+)
+
+const (
+	// schedulePerPage is the page size used when walking an airing schedule window (AniList's maximum)
+	schedulePerPage = 50
+	// maxSchedulePages caps how many pages a single window may span
+	maxSchedulePages = 20
+)
+
+// GetAiringSchedule returns every non-adult episode airing in [from, to), sorted by airing time
+func (c *Client) GetAiringSchedule(ctx context.Context, from, to time.Time) ([]types.AiringSchedule, error) {
+	var schedules []types.AiringSchedule
+
+	for page := 1; page <= maxSchedulePages; page++ {
+		result, err := c.getAiringSchedulePage(ctx, from, to, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch airing schedule page %d: %w", page, err)
+		}
+
+		for _, schedule := range result.Data.Page.AiringSchedules {
+			if !schedule.Media.IsAdult {
+				schedules = append(schedules, schedule)
+			}
+		}
+
+		if !result.Data.Page.PageInfo.HasNextPage {
+			break
+		}
+	}
+
+	return schedules, nil
+}
+
+// getAiringSchedulePage fetches a single page of an airing schedule window
+func (c *Client) getAiringSchedulePage(ctx context.Context, from, to time.Time, page int) (*types.AiringScheduleResponse, error) {
+	cacheKey := scheduleCacheKey(from, to, page)
+
+	var cached types.AiringScheduleResponse
+	if c.readCache(ctx, cacheKey, &cached) {
+		return &cached, nil
+	}
+
+	// AniList's bounds are exclusive, so widen the lower one to include episodes airing exactly at from
+	variables := types.GraphQLAiringScheduleVariables{
+		Page:            page,
+		PerPage:         schedulePerPage,
+		AiringAtGreater: int(from.Unix()) - 1,
+		AiringAtLesser:  int(to.Unix()),
+	}
+
+	var result types.AiringScheduleResponse
+	if err := c.query(ctx, graphql.GetAiringScheduleQuery, variables, &result); err != nil {
+		return nil, err
+	}
+
+	c.writeCache(ctx, cacheKey, result, c.cacheTTL.page)
+
+	return &result, nil
+}
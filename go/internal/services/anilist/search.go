@@ -1,12 +1,7 @@
 package anilist
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"log"
-	"net/http"
-	"os"
+	"context"
 	"strconv"
 	"strings"
 
@@ -20,54 +15,44 @@ import (
 // page: Page number for text search results (ignored for ID search)
 // perPage: Number of results per page for text search (ignored for ID search)
 // Returns: Page containing matching anime with pagination info
-func SearchAnime(query string, page, perPage int) (*types.SearchResponse, error) {
-	anilistAPI := os.Getenv("ANILIST_API")
+func (c *Client) SearchAnime(ctx context.Context, query string, page, perPage int) (*types.SearchResponse, error) {
+	cacheKey := searchCacheKey(query, page, perPage)
+
+	var cached types.SearchResponse
+	if c.readCache(ctx, cacheKey, &cached) {
+		return &cached, nil
+	}
+
+	var result *types.SearchResponse
+	var err error
 
 	// Check if the query is a numeric ID
 	trimmedQuery := strings.TrimSpace(query)
-	if numericID, err := strconv.Atoi(trimmedQuery); err == nil {
+	if numericID, convErr := strconv.Atoi(trimmedQuery); convErr == nil {
 		// Search by ID - return single result in page format
-		return searchAnimeByID(anilistAPI, numericID)
+		result, err = c.searchAnimeByID(ctx, numericID)
+	} else {
+		// Text search
+		result, err = c.searchAnimeByText(ctx, query, page, perPage)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Text search
-	return searchAnimeByText(anilistAPI, query, page, perPage)
+	c.writeCache(ctx, cacheKey, result, c.cacheTTL.search)
+
+	return result, nil
 }
 
 // searchAnimeByID searches for anime by ID and returns it in page format
-func searchAnimeByID(anilistAPI string, animeID int) (*types.SearchResponse, error) {
-
+func (c *Client) searchAnimeByID(ctx context.Context, animeID int) (*types.SearchResponse, error) {
 	variables := types.GraphQLSearchByIDVariables{
 		ID: animeID,
 	}
 
-	requestBody := types.GraphQLRequest[types.GraphQLSearchByIDVariables]{
-		Query:     graphql.SearchAnimeByIDQuery,
-		Variables: variables,
-	}
-
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	resp, err := http.Post(anilistAPI, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Error closing response body: %v", err)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
-	}
-
 	var singleResult types.AniListSingleResponse[types.AnimeMedia]
-	if err := json.NewDecoder(resp.Body).Decode(&singleResult); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := c.query(ctx, graphql.SearchAnimeByIDQuery, variables, &singleResult); err != nil && !IsNotFound(err) {
+		return nil, err
 	}
 
 	// Convert single result to page format
@@ -95,41 +80,16 @@ func searchAnimeByID(anilistAPI string, animeID int) (*types.SearchResponse, err
 }
 
 // searchAnimeByText searches for anime by text query
-func searchAnimeByText(anilistAPI, query string, page, perPage int) (*types.SearchResponse, error) {
-
+func (c *Client) searchAnimeByText(ctx context.Context, query string, page, perPage int) (*types.SearchResponse, error) {
 	variables := types.GraphQLSearchVariables{
 		Search:  query,
 		Page:    page,
 		PerPage: perPage,
 	}
 
-	requestBody := types.GraphQLRequest[types.GraphQLSearchVariables]{
-		Query:     graphql.SearchAnimeByTextQuery,
-		Variables: variables,
-	}
-
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	resp, err := http.Post(anilistAPI, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Error closing response body: %v", err)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
-	}
-
 	var result types.SearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := c.query(ctx, graphql.SearchAnimeByTextQuery, variables, &result); err != nil {
+		return nil, err
 	}
 
 	return &result, nil
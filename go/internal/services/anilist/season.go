@@ -1,12 +1,7 @@
 package anilist
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"log"
-	"net/http"
-	"os"
+	"context"
 	"strings"
 
 	"discord-anime-bot/internal/graphql"
@@ -14,8 +9,13 @@ import (
 )
 
 // GetSeasonAnime gets all anime from a specific season and year
-func GetSeasonAnime(season string, seasonYear int, page, perPage int) (*types.SeasonAnimeResponse, error) {
-	anilistAPI := os.Getenv("ANILIST_API")
+func (c *Client) GetSeasonAnime(ctx context.Context, season string, seasonYear int, page, perPage int) (*types.SeasonAnimeResponse, error) {
+	cacheKey := seasonCacheKey(season, seasonYear, page, perPage)
+
+	var cached types.SeasonAnimeResponse
+	if c.readCache(ctx, cacheKey, &cached) {
+		return &cached, nil
+	}
 
 	variables := types.GraphQLSeasonVariables{
 		Season:     strings.ToUpper(season),
@@ -25,34 +25,12 @@ func GetSeasonAnime(season string, seasonYear int, page, perPage int) (*types.Se
 		PerPage:    perPage,
 	}
 
-	requestBody := types.GraphQLRequest[types.GraphQLSeasonVariables]{
-		Query:     graphql.GetSeasonalAnimeQuery,
-		Variables: variables,
-	}
-
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	resp, err := http.Post(anilistAPI, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Error closing response body: %v", err)
-		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
-	}
-
 	var result types.SeasonAnimeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := c.query(ctx, graphql.GetSeasonalAnimeQuery, variables, &result); err != nil {
+		return nil, err
 	}
 
+	c.writeCache(ctx, cacheKey, result, c.cacheTTL.page)
+
 	return &result, nil
 }
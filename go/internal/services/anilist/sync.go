@@ -0,0 +1,184 @@
+package anilist
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"discord-anime-bot/internal/graphql"
+	"discord-anime-bot/internal/types"
+)
+
+// aniListStatuses maps watchlist statuses to AniList MediaListStatus values
+var aniListStatuses = map[string]string{
+	types.WatchlistPlanning:  "PLANNING",
+	types.WatchlistWatching:  "CURRENT",
+	types.WatchlistCompleted: "COMPLETED",
+	types.WatchlistDropped:   "DROPPED",
+	types.WatchlistPaused:    "PAUSED",
+}
+
+// watchlistStatusFromAniList maps an AniList MediaListStatus to a watchlist status
+// Rewatches count as watching
+func watchlistStatusFromAniList(status string) string {
+	switch status {
+	case "CURRENT", "REPEATING":
+		return types.WatchlistWatching
+	case "COMPLETED":
+		return types.WatchlistCompleted
+	case "DROPPED":
+		return types.WatchlistDropped
+	case "PAUSED":
+		return types.WatchlistPaused
+	default:
+		return types.WatchlistPlanning
+	}
+}
+
+// SyncResult summarizes an import or export between a watchlist and an AniList list
+type SyncResult struct {
+	Added   int
+	Updated int
+	Failed  int
+}
+
+// ImportAniListList copies a user's AniList anime list into their watchlist
+// AniList wins for anime on both lists; anime only on the watchlist are left alone
+func (c *Client) ImportAniListList(ctx context.Context, discordUserID string) (*SyncResult, error) {
+	account, err := c.GetLinkedAccount(discordUserID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := c.accessToken(discordUserID, account)
+	if err != nil {
+		return nil, err
+	}
+
+	var response types.MediaListCollectionResponse
+	variables := types.GraphQLMediaListVariables{UserID: account.AniListUserID}
+	if err := c.queryAs(ctx, token, graphql.GetMediaListCollectionQuery, variables, &response); err != nil {
+		return nil, fmt.Errorf("failed to get AniList list: %w", err)
+	}
+
+	result := &SyncResult{}
+	seen := make(map[int]bool)
+	for _, list := range response.Data.MediaListCollection.Lists {
+		// Custom lists only repeat entries that are already on a status list
+		if list.IsCustomList {
+			continue
+		}
+		for _, remote := range list.Entries {
+			if seen[remote.MediaID] {
+				continue
+			}
+			seen[remote.MediaID] = true
+
+			added, err := c.importMediaListEntry(ctx, discordUserID, remote)
+			if err != nil {
+				log.Printf("Error importing AniList entry %d for user %s: %v", remote.MediaID, discordUserID, err)
+				result.Failed++
+				continue
+			}
+			if added {
+				result.Added++
+			} else {
+				result.Updated++
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// importMediaListEntry saves a single AniList list entry to the watchlist and reports whether it was new
+func (c *Client) importMediaListEntry(ctx context.Context, discordUserID string, remote types.MediaListEntry) (bool, error) {
+	entry, err := c.GetWatchlistEntry(discordUserID, remote.MediaID)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now().Unix()
+	added := entry == nil
+	if added {
+		entry = &types.WatchlistEntry{AnimeID: remote.MediaID, AddedAt: remote.CreatedAt}
+		if entry.AddedAt == 0 {
+			entry.AddedAt = now
+		}
+	}
+
+	entry.Status = watchlistStatusFromAniList(remote.Status)
+	entry.Progress = remote.Progress
+	entry.Score = remote.Score
+	entry.UpdatedAt = remote.UpdatedAt
+	if entry.UpdatedAt == 0 {
+		entry.UpdatedAt = now
+	}
+
+	return added, c.saveWatchlistEntry(ctx, discordUserID, entry)
+}
+
+// ExportWatchlist pushes every entry on a user's watchlist to their AniList list
+func (c *Client) ExportWatchlist(ctx context.Context, discordUserID string) (*SyncResult, error) {
+	account, err := c.GetLinkedAccount(discordUserID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := c.accessToken(discordUserID, account)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := c.GetWatchlistEntries(discordUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{}
+	for _, entry := range entries {
+		if err := c.saveMediaListEntry(ctx, token, &entry); err != nil {
+			log.Printf("Error exporting anime %d for user %s: %v", entry.AnimeID, discordUserID, err)
+			result.Failed++
+			continue
+		}
+		result.Updated++
+	}
+	return result, nil
+}
+
+// SyncWatchlistEntry pushes a single watchlist entry to the user's AniList list
+// It returns ErrNotLinked when the user has no linked account
+func (c *Client) SyncWatchlistEntry(ctx context.Context, discordUserID string, entry *types.WatchlistEntry) error {
+	if !c.oauth.enabled {
+		return ErrNotLinked
+	}
+
+	account, err := c.GetLinkedAccount(discordUserID)
+	if err != nil {
+		return err
+	}
+	token, err := c.accessToken(discordUserID, account)
+	if err != nil {
+		return err
+	}
+	return c.saveMediaListEntry(ctx, token, entry)
+}
+
+// saveMediaListEntry creates or updates the AniList list entry matching a watchlist entry
+func (c *Client) saveMediaListEntry(ctx context.Context, token string, entry *types.WatchlistEntry) error {
+	status, ok := aniListStatuses[entry.Status]
+	if !ok {
+		return fmt.Errorf("unknown watchlist status %q", entry.Status)
+	}
+
+	variables := types.GraphQLSaveMediaListEntryVariables{
+		MediaID:  entry.AnimeID,
+		Status:   status,
+		Progress: entry.Progress,
+		ScoreRaw: int(math.Round(entry.Score * 10)),
+	}
+
+	var response map[string]any
+	return c.queryAs(ctx, token, graphql.SaveMediaListEntryMutation, variables, &response)
+}
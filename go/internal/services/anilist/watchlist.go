@@ -2,36 +2,49 @@ package anilist
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
 	"strconv"
 	"time"
 
-	"discord-anime-bot/internal/services/redis"
+	"discord-anime-bot/internal/services/storage"
+	"discord-anime-bot/internal/types"
 )
 
-const watchlistKeyPrefix = "watchlist:user:"
+const (
+	// watchlistKeyPrefix prefixes the hash of anime ID to JSON watchlist entry; it never expires
+	watchlistKeyPrefix = "watchlist:entries:"
+	// Legacy storage: a set of anime IDs with a 30 day TTL, plus a hash of added times
+	legacyWatchlistKeyPrefix      = "watchlist:user:"
+	legacyWatchlistAddedKeyPrefix = "watchlist:added:"
+)
 
+// ErrNotInWatchlist is returned when updating an anime that is not on the user's watchlist
+var ErrNotInWatchlist = errors.New("anime not found in watchlist")
 
-// AddToWatchlist adds an anime to a user's watchlist
-func AddToWatchlist(userID string, animeID int) (string, error) {
+// AddToWatchlist adds an anime to a user's watchlist with the given status
+func (c *Client) AddToWatchlist(userID string, animeID int, status string) (string, error) {
 	ctx := context.Background()
-	redisKey := watchlistKeyPrefix + userID
 
-	// Check if anime is already in watchlist
-	isInWatchlist, err := redis.SetIsMember(ctx, redisKey, animeID)
+	existing, err := c.GetWatchlistEntry(userID, animeID)
 	if err != nil {
 		return "", err
 	}
-	if isInWatchlist {
+	if existing != nil {
 		return "Anime already in your watchlist.", nil
 	}
 
-	// Add anime to watchlist
-	if err := redis.SetAdd(ctx, redisKey, animeID); err != nil {
-		return "", err
+	now := time.Now().Unix()
+	entry := &types.WatchlistEntry{
+		AnimeID:   animeID,
+		Status:    status,
+		AddedAt:   now,
+		UpdatedAt: now,
 	}
-
-	// Set TTL (30 days)
-	if err := redis.Expire(ctx, redisKey, 30*24*time.Hour); err != nil {
+	if err := c.saveWatchlistEntry(ctx, userID, entry); err != nil {
 		return "", err
 	}
 
@@ -39,45 +52,171 @@ func AddToWatchlist(userID string, animeID int) (string, error) {
 }
 
 // RemoveFromWatchlist removes an anime from a user's watchlist
-func RemoveFromWatchlist(userID string, animeID int) (string, error) {
+func (c *Client) RemoveFromWatchlist(userID string, animeID int) (string, error) {
 	ctx := context.Background()
-	redisKey := watchlistKeyPrefix + userID
 
-	// Check if anime is in watchlist
-	isInWatchlist, err := redis.SetIsMember(ctx, redisKey, animeID)
+	existing, err := c.GetWatchlistEntry(userID, animeID)
 	if err != nil {
 		return "", err
 	}
-	if !isInWatchlist {
+	if existing == nil {
 		return "Anime not found in your watchlist.", nil
 	}
 
-	// Remove anime from watchlist
-	if err := redis.SetRemove(ctx, redisKey, animeID); err != nil {
+	if err := c.store.HashDelete(ctx, watchlistKeyPrefix+userID, strconv.Itoa(animeID)); err != nil {
 		return "", err
 	}
 
 	return "Anime removed from your watchlist.", nil
 }
 
-// GetUserWatchlist returns a user's watchlist
-func GetUserWatchlist(userID string) ([]int, error) {
+// GetWatchlistEntry returns a single watchlist entry, or nil when the anime is not on the watchlist
+func (c *Client) GetWatchlistEntry(userID string, animeID int) (*types.WatchlistEntry, error) {
 	ctx := context.Background()
-	redisKey := watchlistKeyPrefix + userID
 
-	// Get all members of the set
-	members, err := redis.SetMembers(ctx, redisKey)
+	value, err := c.store.HashGet(ctx, watchlistKeyPrefix+userID, strconv.Itoa(animeID))
+	if storage.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry types.WatchlistEntry
+	if err := json.Unmarshal([]byte(value), &entry); err != nil {
+		return nil, fmt.Errorf("invalid watchlist entry for anime %d: %w", animeID, err)
+	}
+	return &entry, nil
+}
+
+// GetWatchlistEntries returns every entry on a user's watchlist
+func (c *Client) GetWatchlistEntries(userID string) ([]types.WatchlistEntry, error) {
+	ctx := context.Background()
+
+	fields, err := c.store.HashGetAll(ctx, watchlistKeyPrefix+userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]types.WatchlistEntry, 0, len(fields))
+	for field, value := range fields {
+		var entry types.WatchlistEntry
+		if err := json.Unmarshal([]byte(value), &entry); err != nil {
+			log.Printf("Skipping invalid watchlist entry %s for user %s: %v", field, userID, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// GetUserWatchlist returns the anime IDs on a user's watchlist
+func (c *Client) GetUserWatchlist(userID string) ([]int, error) {
+	entries, err := c.GetWatchlistEntries(userID)
 	if err != nil {
 		return []int{}, nil // Return empty slice on error
 	}
 
-	// Convert string members to integers
-	var animeIDs []int
+	animeIDs := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		animeIDs = append(animeIDs, entry.AnimeID)
+	}
+	slices.Sort(animeIDs)
+	return animeIDs, nil
+}
+
+// UpdateWatchlistEntry applies update to a watchlist entry and saves it with a fresh updated time
+// It returns ErrNotInWatchlist when the anime is not on the watchlist
+func (c *Client) UpdateWatchlistEntry(userID string, animeID int, update func(entry *types.WatchlistEntry)) (*types.WatchlistEntry, error) {
+	entry, err := c.GetWatchlistEntry(userID, animeID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, ErrNotInWatchlist
+	}
+
+	update(entry)
+	entry.UpdatedAt = time.Now().Unix()
+
+	if err := c.saveWatchlistEntry(context.Background(), userID, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// saveWatchlistEntry stores a watchlist entry in the user's hash
+func (c *Client) saveWatchlistEntry(ctx context.Context, userID string, entry *types.WatchlistEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return c.store.HashSet(ctx, watchlistKeyPrefix+userID, strconv.Itoa(entry.AnimeID), string(data))
+}
+
+// MigrateWatchlists converts watchlists stored as expiring ID sets into entry hashes
+// Migrated anime start out as planning; entries already in the hash are left untouched
+func (c *Client) MigrateWatchlists() {
+	ctx := context.Background()
+	legacyKeys, err := c.store.Keys(ctx, legacyWatchlistKeyPrefix+"*")
+	if err != nil {
+		log.Printf("Error listing legacy watchlists: %v", err)
+		return
+	}
+
+	for _, legacyKey := range legacyKeys {
+		userID := legacyKey[len(legacyWatchlistKeyPrefix):]
+		if err := c.migrateWatchlist(ctx, userID); err != nil {
+			log.Printf("Error migrating watchlist of user %s: %v", userID, err)
+			continue
+		}
+		log.Printf("Migrated watchlist of user %s", userID)
+	}
+}
+
+// migrateWatchlist moves a single user's legacy watchlist into the entry hash and deletes the old keys
+func (c *Client) migrateWatchlist(ctx context.Context, userID string) error {
+	members, err := c.store.SetMembers(ctx, legacyWatchlistKeyPrefix+userID)
+	if err != nil {
+		return err
+	}
+	addedTimes, err := c.store.HashGetAll(ctx, legacyWatchlistAddedKeyPrefix+userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
 	for _, member := range members {
-		if animeID, err := strconv.Atoi(member); err == nil {
-			animeIDs = append(animeIDs, animeID)
+		animeID, err := strconv.Atoi(member)
+		if err != nil {
+			continue
+		}
+
+		existing, err := c.GetWatchlistEntry(userID, animeID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+
+		addedAt, err := strconv.ParseInt(addedTimes[member], 10, 64)
+		if err != nil {
+			addedAt = now
+		}
+		entry := &types.WatchlistEntry{
+			AnimeID:   animeID,
+			Status:    types.WatchlistPlanning,
+			AddedAt:   addedAt,
+			UpdatedAt: now,
+		}
+		if err := c.saveWatchlistEntry(ctx, userID, entry); err != nil {
+			return err
 		}
 	}
 
-	return animeIDs, nil
+	if err := c.store.Delete(ctx, legacyWatchlistKeyPrefix+userID); err != nil {
+		return err
+	}
+	return c.store.Delete(ctx, legacyWatchlistAddedKeyPrefix+userID)
 }
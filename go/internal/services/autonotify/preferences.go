@@ -0,0 +1,98 @@
+package autonotify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"discord-anime-bot/internal/services/storage"
+)
+
+const (
+	// preferenceKeyPrefix prefixes a user's auto-notify opt-in
+	preferenceKeyPrefix = "autonotify:user:"
+	// mutedKeyPrefix prefixes the set of anime whose auto alerts a user cancelled by hand
+	mutedKeyPrefix = "autonotify:muted:"
+)
+
+// Preference is a user's opt-in to alerts for every airing anime on their watchlist
+// Alerts go to ChannelID in GuildID, or to the user's DMs when DM is set
+type Preference struct {
+	UserID    string `json:"userId"`
+	GuildID   string `json:"guildId,omitempty"`
+	ChannelID string `json:"channelId"`
+	DM        bool   `json:"dm,omitempty"`
+	EnabledAt int64  `json:"enabledAt"`
+}
+
+// Preference returns a user's auto-notify preference, or nil when they have not opted in
+func (w *Watcher) Preference(ctx context.Context, userID string) (*Preference, error) {
+	var preference Preference
+	err := w.store.Get(ctx, preferenceKeyPrefix+userID, &preference)
+	if storage.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load auto-notify preference of user %s: %w", userID, err)
+	}
+	return &preference, nil
+}
+
+// preferences returns the preference of every user who opted in
+func (w *Watcher) preferences(ctx context.Context) ([]*Preference, error) {
+	keys, err := w.store.Keys(ctx, preferenceKeyPrefix+"*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list auto-notify preferences: %w", err)
+	}
+
+	all := make([]*Preference, 0, len(keys))
+	for _, key := range keys {
+		var preference Preference
+		if err := w.store.Get(ctx, key, &preference); err != nil {
+			if !storage.IsNotFound(err) {
+				log.Printf("Error loading auto-notify preference %s: %v", key, err)
+			}
+			continue
+		}
+		all = append(all, &preference)
+	}
+	return all, nil
+}
+
+// Enable saves a user's opt-in and forgets the alerts they previously cancelled
+func (w *Watcher) Enable(ctx context.Context, preference *Preference) error {
+	if err := w.store.Set(ctx, preferenceKeyPrefix+preference.UserID, preference, 0); err != nil {
+		return fmt.Errorf("failed to save auto-notify preference of user %s: %w", preference.UserID, err)
+	}
+	return w.store.Delete(ctx, mutedKeyPrefix+preference.UserID)
+}
+
+// Disable removes a user's opt-in
+func (w *Watcher) Disable(ctx context.Context, userID string) error {
+	if err := w.store.Delete(ctx, preferenceKeyPrefix+userID); err != nil {
+		return fmt.Errorf("failed to remove auto-notify preference of user %s: %w", userID, err)
+	}
+	return w.store.Delete(ctx, mutedKeyPrefix+userID)
+}
+
+// Mute stops auto-notify from re-adding alerts for an anime the user cancelled
+func (w *Watcher) Mute(ctx context.Context, userID string, animeID int) error {
+	return w.store.SetAdd(ctx, mutedKeyPrefix+userID, animeID)
+}
+
+// muted returns the anime a user cancelled auto alerts for
+func (w *Watcher) muted(ctx context.Context, userID string) (map[int]bool, error) {
+	members, err := w.store.SetMembers(ctx, mutedKeyPrefix+userID)
+	if err != nil {
+		return nil, err
+	}
+
+	animeIDs := make(map[int]bool, len(members))
+	for _, member := range members {
+		if animeID, err := strconv.Atoi(member); err == nil {
+			animeIDs[animeID] = true
+		}
+	}
+	return animeIDs, nil
+}
@@ -0,0 +1,186 @@
+package autonotify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"discord-anime-bot/internal/services/anilist"
+	"discord-anime-bot/internal/services/storage"
+	"discord-anime-bot/internal/types"
+)
+
+// checkInterval is how often the watcher re-checks every opted-in watchlist
+// New airing dates show up here once the cached AniList details expire
+const checkInterval = 30 * time.Minute
+
+// Watcher keeps an episode subscription for every airing anime on the watchlists of users who opted in
+type Watcher struct {
+	anilist       *anilist.Client
+	notifications *anilist.NotificationService
+	store         storage.Store // opt-in preferences and muted anime
+	stop          chan struct{}
+	wg            sync.WaitGroup
+	mu            sync.Mutex // serializes syncs so a user is never reconciled twice at once
+}
+
+// NewWatcher creates a new auto-notify watcher
+func NewWatcher(client *anilist.Client, notifications *anilist.NotificationService, store storage.Store) *Watcher {
+	return &Watcher{
+		anilist:       client,
+		notifications: notifications,
+		store:         store,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Start begins re-checking opted-in watchlists in the background
+func (w *Watcher) Start() {
+	w.wg.Go(func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			w.checkAll()
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Stop stops the watcher and waits for a check in progress to finish
+func (w *Watcher) Stop() {
+	close(w.stop)
+	w.wg.Wait()
+}
+
+// checkAll reconciles the subscriptions of every user who opted in
+func (w *Watcher) checkAll() {
+	ctx := context.Background()
+	preferences, err := w.preferences(ctx)
+	if err != nil {
+		log.Printf("Error loading auto-notify preferences: %v", err)
+		return
+	}
+
+	for _, preference := range preferences {
+		if _, err := w.sync(ctx, preference); err != nil {
+			log.Printf("Error syncing auto-notify for user %s: %v", preference.UserID, err)
+		}
+	}
+}
+
+// SyncUser reconciles a user's auto subscriptions with their watchlist right away
+// and returns how many anime are tracked; users who have not opted in only get their auto subscriptions removed
+func (w *Watcher) SyncUser(ctx context.Context, userID string) (int, error) {
+	preference, err := w.Preference(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if preference == nil {
+		w.removeAll(userID)
+		return 0, nil
+	}
+	return w.sync(ctx, preference)
+}
+
+// sync adds subscriptions for eligible watchlist anime and removes auto subscriptions that no longer apply
+func (w *Watcher) sync(ctx context.Context, preference *Preference) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	entries, err := w.anilist.GetWatchlistEntries(preference.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get watchlist: %w", err)
+	}
+	mutedIDs, err := w.muted(ctx, preference.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get muted anime: %w", err)
+	}
+
+	var animeIDs []int
+	for _, entry := range entries {
+		if wantsAlerts(entry) && !mutedIDs[entry.AnimeID] {
+			animeIDs = append(animeIDs, entry.AnimeID)
+		}
+	}
+
+	details, err := w.anilist.GetAnimeByIDs(ctx, animeIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get anime details: %w", err)
+	}
+
+	tracked := make(map[int]bool)
+	for _, animeID := range animeIDs {
+		anime := details[animeID]
+		if anime == nil || !isAiring(anime) {
+			continue
+		}
+		tracked[animeID] = true
+
+		// Leave existing subscriptions alone, including ones the user set up by hand
+		if w.notifications.HasNotification(animeID, preference.ChannelID, preference.UserID) {
+			continue
+		}
+		err := w.notifications.AddAutoSubscription(animeID, preference.GuildID, preference.ChannelID, preference.UserID, anime.NextAiringEpisode, preference.DM)
+		if err != nil {
+			log.Printf("Error adding auto-notify subscription for anime %d, user %s: %v", animeID, preference.UserID, err)
+		}
+	}
+
+	// Drop auto subscriptions for anime that left the watchlist or were sent to another channel
+	for _, notification := range w.notifications.GetUserNotifications(preference.UserID) {
+		if !notification.Auto {
+			continue
+		}
+		if tracked[notification.AnimeID] && notification.ChannelID == preference.ChannelID && notification.DM == preference.DM {
+			continue
+		}
+		w.remove(notification)
+	}
+
+	return len(tracked), nil
+}
+
+// removeAll removes every auto subscription of a user
+func (w *Watcher) removeAll(userID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, notification := range w.notifications.GetUserNotifications(userID) {
+		if notification.Auto {
+			w.remove(notification)
+		}
+	}
+}
+
+// remove cancels a single auto subscription
+func (w *Watcher) remove(notification *types.NotificationEntry) {
+	if err := w.notifications.RemoveNotification(notification.AnimeID, notification.ChannelID, notification.UserID); err != nil {
+		log.Printf("Error removing auto-notify subscription for anime %d, user %s: %v", notification.AnimeID, notification.UserID, err)
+	}
+}
+
+// wantsAlerts reports whether a watchlist entry's status asks for episode alerts
+// Paused, completed and dropped anime stay quiet
+func wantsAlerts(entry types.WatchlistEntry) bool {
+	return entry.Status == types.WatchlistPlanning || entry.Status == types.WatchlistWatching
+}
+
+// isAiring reports whether an anime has episodes to alert for: it is releasing,
+// or it has not started yet but its first episode now has an airing date
+func isAiring(anime *types.AnimeDetails) bool {
+	switch anime.Status {
+	case "RELEASING":
+		return true
+	case "NOT_YET_RELEASED":
+		return anime.NextAiringEpisode != nil
+	default:
+		return false
+	}
+}
@@ -2,47 +2,102 @@ package claude
 
 import (
 	"context"
-	"os"
+	"fmt"
+	"log"
+
+	"discord-anime-bot/internal/services/ai"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 )
 
-type ClaudeClient struct {
-	client *anthropic.Client
+// Recommender finds anime recommendations using Claude
+type Recommender struct {
+	client anthropic.Client
+	model  string
 }
 
-func NewClaudeClient() *ClaudeClient {
-	apiKey := os.Getenv("CLAUDE_API_KEY")
-	if apiKey == "" {
-		return nil
+// NewRecommender creates a Claude-backed recommender for the given model
+func NewRecommender(apiKey, model string) *Recommender {
+	return &Recommender{
+		client: anthropic.NewClient(option.WithAPIKey(apiKey)),
+		model:  model,
 	}
-	client := anthropic.NewClient(option.WithAPIKey(apiKey))
-	return &ClaudeClient{client: &client}
 }
 
-func (c *ClaudeClient) FindAnimeByDescription(prompt string) (string, error) {
-	if c == nil || c.client == nil {
-		return "", nil
-	}
-	params := anthropic.MessageNewParams{
-		Model:     anthropic.ModelClaudeSonnet4_5,
-		MaxTokens: 2048,
-		Messages: []anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(
-				`You are an anime expert. Given a description, recommend 3 anime titles that match. Respond ONLY with a valid JSON array in this format:
-[
-  {"title":"Anime Name","reason":"Why it matches","confidence":0.9}
-]
-` + prompt)),
+// Name returns the provider name
+func (r *Recommender) Name() string {
+	return "Claude"
+}
+
+// Recommend uses Claude to find anime recommendations based on description
+// Claude is forced to answer through a tool whose input schema is the recommendations
+// object; input that still fails validation gets one repair round before giving up
+func (r *Recommender) Recommend(ctx context.Context, description string) (*ai.Result, error) {
+	tool := anthropic.ToolParam{
+		Name:        ai.SchemaName,
+		Description: anthropic.String(ai.SchemaDescription),
+		InputSchema: anthropic.ToolInputSchemaParam{
+			Properties: ai.SchemaProperties(),
+			Required:   ai.SchemaRequired(),
 		},
 	}
-	message, err := c.client.Messages.New(context.TODO(), params)
-	if err != nil {
-		return "", err
+
+	messages := []anthropic.MessageParam{
+		anthropic.NewUserMessage(anthropic.NewTextBlock(ai.BuildPrompt(description))),
 	}
-	if len(message.Content) > 0 {
-		return message.Content[0].Text, nil
+
+	for attempt := 0; ; attempt++ {
+		params := anthropic.MessageNewParams{
+			Model:      anthropic.Model(r.model),
+			MaxTokens:  2048,
+			Messages:   messages,
+			Tools:      []anthropic.ToolUnionParam{{OfTool: &tool}},
+			ToolChoice: anthropic.ToolChoiceParamOfTool(ai.SchemaName),
+		}
+
+		message, err := r.client.Messages.New(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("Claude API error: %w", err)
+		}
+
+		// Prefer the tool input, but fall back to any text Claude returned instead
+		var toolUseID, content string
+		for _, block := range message.Content {
+			switch block.Type {
+			case "tool_use":
+				if block.Name == ai.SchemaName {
+					toolUseID, content = block.ID, string(block.Input)
+				}
+			case "text":
+				if content == "" {
+					content = block.Text
+				}
+			}
+		}
+
+		if content == "" {
+			return nil, fmt.Errorf("no response from Claude")
+		}
+
+		recommendations, err := ai.DecodeRecommendations(content)
+		if err == nil {
+			return &ai.Result{
+				Attribution:     r.Name() + " " + r.model,
+				Recommendations: recommendations,
+			}, nil
+		}
+
+		if attempt >= ai.MaxRepairAttempts {
+			return nil, fmt.Errorf("failed to parse Claude response: %w", err)
+		}
+
+		log.Printf("Claude returned malformed recommendations, asking for a repair: %v", err)
+		repair := anthropic.NewTextBlock(ai.RepairPrompt(err))
+		if toolUseID != "" {
+			// A tool_use block must be answered with a matching tool_result
+			repair = anthropic.NewToolResultBlock(toolUseID, ai.RepairPrompt(err), true)
+		}
+		messages = append(messages, message.ToParam(), anthropic.NewUserMessage(repair))
 	}
-	return "", nil
 }
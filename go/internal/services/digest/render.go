@@ -0,0 +1,190 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"discord-anime-bot/internal/services/settings"
+	"discord-anime-bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// Discord limits: description length, embeds per message and total embed text per message
+	maxDescriptionLength = 4000
+	maxEmbedsPerMessage  = 10
+	maxMessageEmbedText  = 6000
+
+	digestColor = 0x02A9FF
+)
+
+// post fetches the episodes airing in the guild's digest window and posts the digest
+func (s *Scheduler) post(ctx context.Context, guild *settings.Settings, from time.Time) error {
+	schedules, err := s.anilist.GetAiringSchedule(ctx, from, from.Add(period(guild)))
+	if err != nil {
+		return err
+	}
+
+	var embeds []*discordgo.MessageEmbed
+	if guild.DigestFrequency == settings.DigestWeekly {
+		embeds = weeklyEmbeds(schedules, guild)
+	} else {
+		embeds = dailyEmbeds(schedules, guild)
+	}
+
+	content := ""
+	if guild.PingRoleID != "" {
+		content = fmt.Sprintf("<@&%s>", guild.PingRoleID)
+	}
+
+	return sendEmbeds(s.session, guild.DigestChannelID, content, embeds)
+}
+
+// dailyEmbeds lists every episode of the next 24 hours in airing order
+func dailyEmbeds(schedules []types.AiringSchedule, guild *settings.Settings) []*discordgo.MessageEmbed {
+	if len(schedules) == 0 {
+		return []*discordgo.MessageEmbed{{
+			Title:       "📅 Airing in the next 24 hours",
+			Description: "No episodes are scheduled to air.",
+			Color:       digestColor,
+		}}
+	}
+
+	embeds := listEmbeds("📅 Airing in the next 24 hours", scheduleLines(schedules, guild))
+	embeds[len(embeds)-1].Footer = &discordgo.MessageEmbedFooter{
+		Text: fmt.Sprintf("%d episodes · Times are shown in your local time", len(schedules)),
+	}
+	return embeds
+}
+
+// weeklyEmbeds lists the episodes of the next 7 days grouped by weekday in the guild's time zone
+func weeklyEmbeds(schedules []types.AiringSchedule, guild *settings.Settings) []*discordgo.MessageEmbed {
+	if len(schedules) == 0 {
+		return []*discordgo.MessageEmbed{{
+			Title:       "📅 Airing this week",
+			Description: "No episodes are scheduled to air.",
+			Color:       digestColor,
+		}}
+	}
+
+	location := guild.Location()
+	var embeds []*discordgo.MessageEmbed
+	for _, day := range GroupByDay(schedules, location) {
+		title := "📅 " + day.Date.Format("Monday, January 2")
+		embeds = append(embeds, listEmbeds(title, scheduleLines(day.Schedules, guild))...)
+	}
+	embeds[len(embeds)-1].Footer = &discordgo.MessageEmbedFooter{
+		Text: fmt.Sprintf("%d episodes · Days follow the %s time zone", len(schedules), location),
+	}
+	return embeds
+}
+
+// Day is the airing schedule of a single calendar day
+type Day struct {
+	Date      time.Time
+	Schedules []types.AiringSchedule
+}
+
+// GroupByDay groups airing-ordered schedules by calendar day in the given time zone
+func GroupByDay(schedules []types.AiringSchedule, location *time.Location) []Day {
+	var days []Day
+	for _, schedule := range schedules {
+		local := time.Unix(int64(schedule.AiringAt), 0).In(location)
+		date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(date) {
+			days = append(days, Day{Date: date})
+		}
+		days[len(days)-1].Schedules = append(days[len(days)-1].Schedules, schedule)
+	}
+	return days
+}
+
+// scheduleLines formats one line per episode with a Discord timestamp
+func scheduleLines(schedules []types.AiringSchedule, guild *settings.Settings) []string {
+	lines := make([]string, 0, len(schedules))
+	for _, schedule := range schedules {
+		lines = append(lines, FormatScheduleLine(schedule, guild))
+	}
+	return lines
+}
+
+// FormatScheduleLine formats an airing episode as "<time> Title · Episode N"
+func FormatScheduleLine(schedule types.AiringSchedule, guild *settings.Settings) string {
+	title := guild.Title(schedule.Media.Title)
+	if schedule.Media.SiteURL != "" {
+		title = fmt.Sprintf("[%s](%s)", title, schedule.Media.SiteURL)
+	}
+	return fmt.Sprintf("<t:%d:t> **%s** · Episode %d", schedule.AiringAt, title, schedule.Episode)
+}
+
+// listEmbeds splits lines across as many embeds as the description limit requires
+func listEmbeds(title string, lines []string) []*discordgo.MessageEmbed {
+	var embeds []*discordgo.MessageEmbed
+	var description strings.Builder
+
+	flush := func() {
+		embedTitle := title
+		if len(embeds) > 0 {
+			embedTitle += " (continued)"
+		}
+		embeds = append(embeds, &discordgo.MessageEmbed{
+			Title:       embedTitle,
+			Description: description.String(),
+			Color:       digestColor,
+		})
+		description.Reset()
+	}
+
+	for _, line := range lines {
+		if description.Len() > 0 && description.Len()+len(line)+1 > maxDescriptionLength {
+			flush()
+		}
+		description.WriteString(line + "\n")
+	}
+	flush()
+
+	return embeds
+}
+
+// sendEmbeds posts embeds to a channel, packing as many into each message as Discord allows
+// The content is only attached to the first message
+func sendEmbeds(session *discordgo.Session, channelID, content string, embeds []*discordgo.MessageEmbed) error {
+	var batch []*discordgo.MessageEmbed
+	batchSize := 0
+
+	send := func() error {
+		_, err := session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+			Content: content,
+			Embeds:  batch,
+		})
+		content = ""
+		batch = nil
+		batchSize = 0
+		return err
+	}
+
+	for _, embed := range embeds {
+		size := len(embed.Title) + len(embed.Description)
+		if embed.Footer != nil {
+			size += len(embed.Footer.Text)
+		}
+
+		if len(batch) > 0 && (len(batch) == maxEmbedsPerMessage || batchSize+size > maxMessageEmbedText) {
+			if err := send(); err != nil {
+				return fmt.Errorf("failed to send digest to channel %s: %w", channelID, err)
+			}
+		}
+		batch = append(batch, embed)
+		batchSize += size
+	}
+
+	if len(batch) > 0 {
+		if err := send(); err != nil {
+			return fmt.Errorf("failed to send digest to channel %s: %w", channelID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"discord-anime-bot/internal/services/anilist"
+	"discord-anime-bot/internal/services/settings"
+	"discord-anime-bot/internal/services/storage"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// checkInterval is how often the scheduler looks for digests that are due
+	checkInterval = time.Minute
+	// nextRunKeyPrefix prefixes the key holding a guild's next digest run
+	nextRunKeyPrefix = "digest:next:"
+)
+
+// nextRun is the persisted next digest run of a guild
+// Schedule records the settings the run was computed from, so changing them reschedules the digest
+type nextRun struct {
+	RunAt    int64  `json:"runAt"`
+	Schedule string `json:"schedule"`
+}
+
+// Scheduler posts airing schedule digests to the channels guilds configured
+type Scheduler struct {
+	session *discordgo.Session
+	anilist *anilist.Client
+	guilds  *settings.Service
+	store   storage.Store
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewScheduler creates a new digest scheduler
+func NewScheduler(session *discordgo.Session, client *anilist.Client, guilds *settings.Service, store storage.Store) *Scheduler {
+	return &Scheduler{
+		session: session,
+		anilist: client,
+		guilds:  guilds,
+		store:   store,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start begins checking for due digests in the background
+func (s *Scheduler) Start() {
+	s.wg.Go(func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			s.checkAll()
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Stop stops the scheduler and waits for a digest in progress to finish
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// NextRun returns the first digest time strictly after the given time in the guild's time zone
+func NextRun(guild *settings.Settings, after time.Time) time.Time {
+	location := guild.Location()
+	hour, minute := guild.DigestClock()
+
+	local := after.In(location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, location)
+	for !next.After(after) || (guild.DigestFrequency == settings.DigestWeekly && next.Weekday() != guild.DigestWeekday) {
+		// AddDate keeps the wall clock time across daylight saving changes
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// period returns how far ahead a guild's digest looks
+func period(guild *settings.Settings) time.Duration {
+	if guild.DigestFrequency == settings.DigestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// scheduleSignature identifies the settings a run time was computed from
+func scheduleSignature(guild *settings.Settings) string {
+	hour, minute := guild.DigestClock()
+	return fmt.Sprintf("%s %02d:%02d %s %d %s", guild.DigestFrequency, hour, minute, guild.Location(), guild.DigestWeekday, guild.DigestChannelID)
+}
+
+// checkAll posts the digests of every guild whose run time has come
+func (s *Scheduler) checkAll() {
+	ctx := context.Background()
+	guilds, err := s.guilds.All(ctx)
+	if err != nil {
+		log.Printf("Error loading guild settings for digests: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, guild := range guilds {
+		if guild.DigestEnabled() {
+			s.checkGuild(ctx, guild, now)
+		}
+	}
+}
+
+// checkGuild posts a guild's digest if it is due and persists the following run time
+func (s *Scheduler) checkGuild(ctx context.Context, guild *settings.Settings, now time.Time) {
+	key := nextRunKeyPrefix + guild.GuildID
+	signature := scheduleSignature(guild)
+
+	var stored nextRun
+	err := s.store.Get(ctx, key, &stored)
+	if err != nil && !storage.IsNotFound(err) {
+		log.Printf("Error loading next digest run for guild %s: %v", guild.GuildID, err)
+		return
+	}
+
+	runAt := time.Unix(stored.RunAt, 0)
+	due := err == nil && stored.Schedule == signature && !now.Before(runAt)
+	// A run missed by a whole period (e.g. the digest was switched off and on again) is not worth posting late
+	stale := due && now.Sub(runAt) >= period(guild)
+
+	if err == nil && stored.Schedule == signature && !due {
+		return
+	}
+
+	// Persist the following run before posting, so a restart mid-post can never post the same digest twice
+	next := nextRun{RunAt: NextRun(guild, now).Unix(), Schedule: signature}
+	if err := s.store.Set(ctx, key, next, 0); err != nil {
+		log.Printf("Error saving next digest run for guild %s: %v", guild.GuildID, err)
+		return
+	}
+
+	if !due || stale {
+		log.Printf("Scheduled %s digest for guild %s at %v", guild.DigestFrequency, guild.GuildID, time.Unix(next.RunAt, 0))
+		return
+	}
+
+	if err := s.post(ctx, guild, now); err != nil {
+		log.Printf("Error posting %s digest for guild %s: %v", guild.DigestFrequency, guild.GuildID, err)
+		return
+	}
+	log.Printf("Posted %s digest for guild %s", guild.DigestFrequency, guild.GuildID)
+}
@@ -2,65 +2,91 @@ package openai
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"log"
 
-	"discord-anime-bot/internal/types"
+	"discord-anime-bot/internal/services/ai"
 
 	"github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/option"
+	"github.com/openai/openai-go/v2/shared"
 )
 
-// FindAnimeByDescription uses OpenAI to find anime recommendations based on description
-func FindAnimeByDescription(description, apiKey string) ([]types.OpenAIRecommendation, error) {
-	if apiKey == "" {
-		return nil, fmt.Errorf("OpenAI is not configured. Please set OPENAI_API_KEY environment variable")
+// Recommender finds anime recommendations using OpenAI chat completions
+type Recommender struct {
+	client openai.Client
+	model  string
+}
+
+// NewRecommender creates an OpenAI-backed recommender for the given model
+func NewRecommender(apiKey, model string) *Recommender {
+	return &Recommender{
+		client: openai.NewClient(option.WithAPIKey(apiKey)),
+		model:  model,
 	}
+}
 
-	client := openai.NewClient(option.WithAPIKey(apiKey))
+// Name returns the provider name
+func (r *Recommender) Name() string {
+	return "OpenAI"
+}
 
-	prompt := fmt.Sprintf(`Based on this description: "%s"
+// Recommend uses OpenAI to find anime recommendations based on description
+// The response is constrained with a strict JSON schema; output that still fails
+// validation gets one repair round before giving up
+func (r *Recommender) Recommend(ctx context.Context, description string) (*ai.Result, error) {
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.UserMessage(ai.BuildPrompt(description)),
+	}
 
-Please recommend anime titles that match this description. Return your response as a JSON array of objects with the following structure:
-[
-  {
-    "title": "Exact anime title",
-    "reason": "Brief explanation of why this matches",
-    "confidence": 0.95
-  }
-]
+	for attempt := 0; ; attempt++ {
+		resp, err := r.client.Chat.Completions.New(
+			ctx,
+			openai.ChatCompletionNewParams{
+				Model:    openai.ChatModel(r.model),
+				Messages: messages,
+				ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
+					OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+						JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+							Name:        ai.SchemaName,
+							Description: openai.String(ai.SchemaDescription),
+							Schema:      ai.Schema(),
+							Strict:      openai.Bool(true),
+						},
+					},
+				},
+			},
+		)
 
-Guidelines:
-- Return 1-3 recommendations
-- Use exact anime titles (romaji or English)
-- Confidence should be between 0.0 and 1.0
-- Only return valid JSON, no other text
-- Focus on popular/well-known anime`, description)
+		if err != nil {
+			return nil, fmt.Errorf("OpenAI API error: %w", err)
+		}
 
-	resp, err := client.Chat.Completions.New(
-		context.Background(),
-		openai.ChatCompletionNewParams{
-			Model: openai.ChatModelGPT5, // Uses the latest model
-			Messages: []openai.ChatCompletionMessageParamUnion{
-				openai.UserMessage(prompt),
-			},
-		},
-	)
+		if len(resp.Choices) == 0 {
+			return nil, fmt.Errorf("no response from OpenAI")
+		}
 
-	if err != nil {
-		return nil, fmt.Errorf("OpenAI API error: %w", err)
-	}
+		message := resp.Choices[0].Message
+		if message.Refusal != "" {
+			return nil, fmt.Errorf("OpenAI refused the request: %s", message.Refusal)
+		}
 
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from OpenAI")
-	}
+		recommendations, err := ai.DecodeRecommendations(message.Content)
+		if err == nil {
+			return &ai.Result{
+				Attribution:     r.Name() + " " + r.model,
+				Recommendations: recommendations,
+			}, nil
+		}
 
-	content := resp.Choices[0].Message.Content
+		if attempt >= ai.MaxRepairAttempts {
+			return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
+		}
 
-	var recommendations []types.OpenAIRecommendation
-	if err := json.Unmarshal([]byte(content), &recommendations); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
+		log.Printf("OpenAI returned malformed recommendations, asking for a repair: %v", err)
+		messages = append(messages,
+			openai.AssistantMessage(message.Content),
+			openai.UserMessage(ai.RepairPrompt(err)),
+		)
 	}
-
-	return recommendations, nil
 }
@@ -0,0 +1,183 @@
+package settings
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"discord-anime-bot/internal/services/storage"
+	"discord-anime-bot/internal/types"
+)
+
+// Title languages a guild can prefer for anime titles
+const (
+	TitleLanguageEnglish = "english"
+	TitleLanguageRomaji  = "romaji"
+	TitleLanguageNative  = "native"
+)
+
+// Digest frequencies; an empty frequency means the guild gets no digest
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// DefaultDigestTime is the local time digests are posted at when none is configured
+const DefaultDigestTime = "09:00"
+
+// ToggleableCommands are the /anime subcommands a guild can disable; help always stays available
+// and find is controlled separately through AIFindDisabled
+var ToggleableCommands = []string{"search", "next", "notify", "watchlist", "release", "season", "schedule"}
+
+// Settings holds the configuration of a single guild
+// The zero value is the default configuration, so guilds that never ran /anime-config need no stored entry
+type Settings struct {
+	GuildID               string   `json:"guildId"`
+	NotificationChannelID string   `json:"notificationChannelId,omitempty"` // where episode alerts are posted, empty means the channel they were set up in
+	PingRoleID            string   `json:"pingRoleId,omitempty"`            // role mentioned alongside episode alerts
+	TitleLanguage         string   `json:"titleLanguage,omitempty"`         // english (default), romaji or native
+	Timezone              string   `json:"timezone,omitempty"`              // IANA name, empty means UTC
+	DisabledCommands      []string `json:"disabledCommands,omitempty"`
+	AIFindDisabled        bool     `json:"aiFindDisabled,omitempty"`
+
+	// Airing schedule digest
+	DigestFrequency string       `json:"digestFrequency,omitempty"` // daily, weekly or empty when disabled
+	DigestChannelID string       `json:"digestChannelId,omitempty"`
+	DigestTime      string       `json:"digestTime,omitempty"`    // local "15:04" time, DefaultDigestTime when empty
+	DigestWeekday   time.Weekday `json:"digestWeekday,omitempty"` // weekday of weekly digests
+}
+
+// Service loads and saves guild settings
+type Service struct {
+	store storage.Store
+}
+
+// NewService creates a settings service backed by store
+func NewService(store storage.Store) *Service {
+	return &Service{store: store}
+}
+
+// Get returns the settings of a guild, or the defaults when none are stored
+// Interactions outside guilds (DMs) have no guild ID and always get the defaults
+func (s *Service) Get(ctx context.Context, guildID string) (*Settings, error) {
+	settings := &Settings{GuildID: guildID}
+	if guildID == "" {
+		return settings, nil
+	}
+
+	err := s.store.Get(ctx, storageKey(guildID), settings)
+	if storage.IsNotFound(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return &Settings{GuildID: guildID}, fmt.Errorf("failed to load settings for guild %s: %w", guildID, err)
+	}
+	return settings, nil
+}
+
+// All returns the stored settings of every guild
+func (s *Service) All(ctx context.Context) ([]*Settings, error) {
+	keys, err := s.store.Keys(ctx, storageKey("*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list guild settings: %w", err)
+	}
+
+	all := make([]*Settings, 0, len(keys))
+	for _, key := range keys {
+		var guild Settings
+		if err := s.store.Get(ctx, key, &guild); err != nil {
+			if !storage.IsNotFound(err) {
+				log.Printf("Error loading guild settings %s: %v", key, err)
+			}
+			continue
+		}
+		all = append(all, &guild)
+	}
+	return all, nil
+}
+
+// Save persists the settings of a guild
+func (s *Service) Save(ctx context.Context, settings *Settings) error {
+	if err := s.store.Set(ctx, storageKey(settings.GuildID), settings, 0); err != nil {
+		return fmt.Errorf("failed to save settings for guild %s: %w", settings.GuildID, err)
+	}
+	return nil
+}
+
+// Reset removes the stored settings of a guild, restoring the defaults
+func (s *Service) Reset(ctx context.Context, guildID string) error {
+	if err := s.store.Delete(ctx, storageKey(guildID)); err != nil {
+		return fmt.Errorf("failed to reset settings for guild %s: %w", guildID, err)
+	}
+	return nil
+}
+
+// CommandEnabled reports whether an /anime subcommand may be used in the guild
+func (s *Settings) CommandEnabled(name string) bool {
+	if name == "find" && s.AIFindDisabled {
+		return false
+	}
+	return !slices.Contains(s.DisabledCommands, name)
+}
+
+// SetCommandEnabled enables or disables an /anime subcommand
+func (s *Settings) SetCommandEnabled(name string, enabled bool) {
+	s.DisabledCommands = slices.DeleteFunc(s.DisabledCommands, func(disabled string) bool {
+		return disabled == name
+	})
+	if !enabled {
+		s.DisabledCommands = append(s.DisabledCommands, name)
+		slices.Sort(s.DisabledCommands)
+	}
+}
+
+// Location returns the guild's time zone, falling back to UTC when unset or invalid
+func (s *Settings) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// DigestEnabled reports whether the guild has a digest scheduled
+func (s *Settings) DigestEnabled() bool {
+	return s.DigestFrequency != "" && s.DigestChannelID != ""
+}
+
+// DigestClock returns the hour and minute digests are posted at in the guild's time zone
+func (s *Settings) DigestClock() (hour, minute int) {
+	clock, err := time.Parse("15:04", s.DigestTime)
+	if err != nil {
+		clock, _ = time.Parse("15:04", DefaultDigestTime)
+	}
+	return clock.Hour(), clock.Minute()
+}
+
+// Title picks the title to display in the guild's preferred language, falling back to romaji
+func (s *Settings) Title(title types.AnimeTitle) string {
+	switch s.TitleLanguage {
+	case TitleLanguageRomaji:
+		return title.Romaji
+	case TitleLanguageNative:
+		if title.Native != "" {
+			return title.Native
+		}
+		return title.Romaji
+	default:
+		if title.English != nil && *title.English != "" {
+			return *title.English
+		}
+		return title.Romaji
+	}
+}
+
+// storageKey returns the key holding a guild's settings
+func storageKey(guildID string) string {
+	return "guild:settings:" + guildID
+}
@@ -0,0 +1,792 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errWrongType mirrors Redis' WRONGTYPE error for operations on a key holding another kind of value
+var errWrongType = errors.New("storage: operation against a key holding the wrong kind of value")
+
+// memoryItem is a single key of the in-memory store; exactly one of the value fields is in use
+type memoryItem struct {
+	Value     *string            `json:"value,omitempty"`
+	Set       map[string]bool    `json:"set,omitempty"`
+	Hash      map[string]string  `json:"hash,omitempty"`
+	SortedSet map[string]float64 `json:"sortedSet,omitempty"`
+	List      []string           `json:"list,omitempty"`
+	ExpiresAt int64              `json:"expiresAt,omitempty"` // Unix milliseconds, 0 when the key never expires
+}
+
+// expired reports whether the item's TTL has passed
+func (item *memoryItem) expired(now time.Time) bool {
+	return item.ExpiresAt > 0 && now.UnixMilli() >= item.ExpiresAt
+}
+
+// empty reports whether a collection item has lost its last member, which removes the key like in Redis
+func (item *memoryItem) empty() bool {
+	return item.Value == nil && len(item.Set) == 0 && len(item.Hash) == 0 && len(item.SortedSet) == 0 && len(item.List) == 0
+}
+
+// Memory is a Store that keeps everything in process memory
+// With a snapshot path it is saved to that file periodically and on Close, and reloaded on start
+type Memory struct {
+	mu    sync.Mutex
+	items map[string]*memoryItem
+	dirty bool
+
+	snapshotPath string
+	stop         chan struct{}
+	closeOnce    sync.Once
+	wg           sync.WaitGroup
+}
+
+// NewMemory creates an in-memory store
+// An empty snapshotPath keeps the data only for the lifetime of the process
+func NewMemory(snapshotPath string, snapshotInterval time.Duration) (*Memory, error) {
+	m := &Memory{
+		items:        make(map[string]*memoryItem),
+		snapshotPath: snapshotPath,
+		stop:         make(chan struct{}),
+	}
+	if snapshotPath == "" {
+		log.Println("Using in-memory storage (data is lost on restart)")
+		return m, nil
+	}
+
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	log.Printf("Using in-memory storage with snapshots in %s (%d keys loaded)", snapshotPath, len(m.items))
+
+	if snapshotInterval > 0 {
+		m.wg.Go(func() {
+			m.snapshotLoop(snapshotInterval)
+		})
+	}
+	return m, nil
+}
+
+// item returns a live item, dropping it when it has expired (must hold lock)
+func (m *Memory) item(key string) *memoryItem {
+	item, exists := m.items[key]
+	if !exists {
+		return nil
+	}
+	if item.expired(time.Now()) {
+		delete(m.items, key)
+		m.dirty = true
+		return nil
+	}
+	return item
+}
+
+// collection returns the item of a set, hash, sorted set or list key, creating it when create is set (must hold lock)
+// kind picks which collection the key must hold
+func (m *Memory) collection(key string, kind func(*memoryItem) bool, create func() *memoryItem) (*memoryItem, error) {
+	item := m.item(key)
+	if item == nil {
+		if create == nil {
+			return nil, nil
+		}
+		item = create()
+		m.items[key] = item
+		return item, nil
+	}
+	if !kind(item) {
+		return nil, errWrongType
+	}
+	return item, nil
+}
+
+func isSet(item *memoryItem) bool       { return item.Set != nil }
+func isHash(item *memoryItem) bool      { return item.Hash != nil }
+func isSortedSet(item *memoryItem) bool { return item.SortedSet != nil }
+func isList(item *memoryItem) bool      { return item.List != nil }
+
+func newSet() *memoryItem       { return &memoryItem{Set: make(map[string]bool)} }
+func newHash() *memoryItem      { return &memoryItem{Hash: make(map[string]string)} }
+func newSortedSet() *memoryItem { return &memoryItem{SortedSet: make(map[string]float64)} }
+func newList() *memoryItem      { return &memoryItem{List: []string{}} }
+
+// removeIfEmpty deletes a collection key that lost its last member (must hold lock)
+func (m *Memory) removeIfEmpty(key string, item *memoryItem) {
+	if item != nil && item.empty() {
+		delete(m.items, key)
+	}
+}
+
+// set stores a string value (must hold lock)
+func (m *Memory) set(key string, data string, ttl time.Duration) {
+	item := &memoryItem{Value: &data}
+	if ttl > 0 {
+		item.ExpiresAt = time.Now().Add(ttl).UnixMilli()
+	}
+	m.items[key] = item
+	m.dirty = true
+}
+
+// Set stores a key-value pair with optional TTL
+func (m *Memory) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.set(key, data, ttl)
+	return nil
+}
+
+// SetNX stores a key-value pair only when the key does not exist yet and reports whether it was stored
+func (m *Memory) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	data, err := encodeValue(value)
+	if err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.item(key) != nil {
+		return false, nil
+	}
+	m.set(key, data, ttl)
+	return true, nil
+}
+
+// getString returns a string value (must hold lock)
+func (m *Memory) getString(key string) (string, error) {
+	item := m.item(key)
+	if item == nil {
+		return "", ErrNotFound
+	}
+	if item.Value == nil {
+		return "", errWrongType
+	}
+	return *item.Value, nil
+}
+
+// Get retrieves a value by key and optionally unmarshals JSON
+func (m *Memory) Get(ctx context.Context, key string, dest any) error {
+	m.mu.Lock()
+	data, err := m.getString(key)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return decodeValue(data, dest)
+}
+
+// GetDelete retrieves a string value and removes its key in one step
+func (m *Memory) GetDelete(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, err := m.getString(key)
+	if err != nil {
+		return "", err
+	}
+	delete(m.items, key)
+	m.dirty = true
+	return data, nil
+}
+
+// MGet returns the string values of several keys
+// Missing keys are reported as false at their position
+func (m *Memory) MGet(ctx context.Context, keys ...string) ([]string, []bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	values := make([]string, len(keys))
+	found := make([]bool, len(keys))
+	for index, key := range keys {
+		if data, err := m.getString(key); err == nil {
+			values[index] = data
+			found[index] = true
+		}
+	}
+	return values, found, nil
+}
+
+// delete removes keys (must hold lock)
+func (m *Memory) delete(keys ...string) {
+	for _, key := range keys {
+		delete(m.items, key)
+	}
+	m.dirty = true
+}
+
+// Delete removes keys
+func (m *Memory) Delete(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.delete(keys...)
+	return nil
+}
+
+// DeleteIfValue removes a key only when it holds value and reports whether it was removed
+func (m *Memory) DeleteIfValue(ctx context.Context, key, value string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, err := m.getString(key)
+	if err != nil || data != value {
+		return false, nil
+	}
+	m.delete(key)
+	return true, nil
+}
+
+// Exists checks if a key exists
+func (m *Memory) Exists(ctx context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.item(key) != nil, nil
+}
+
+// Keys returns all keys matching a glob pattern such as "notification:*"
+func (m *Memory) Keys(ctx context.Context, pattern string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var keys []string
+	for key := range m.items {
+		matched, err := path.Match(pattern, key)
+		if err != nil {
+			return nil, err
+		}
+		if matched && m.item(key) != nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// Expire sets TTL on a key
+func (m *Memory) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item := m.item(key)
+	if item == nil {
+		return nil
+	}
+	if ttl <= 0 {
+		m.delete(key)
+		return nil
+	}
+	item.ExpiresAt = time.Now().Add(ttl).UnixMilli()
+	m.dirty = true
+	return nil
+}
+
+// TTL gets the time to live for a key
+// Like Redis it returns -2 for a missing key and -1 for a key without expiry
+func (m *Memory) TTL(ctx context.Context, key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item := m.item(key)
+	switch {
+	case item == nil:
+		return -2, nil
+	case item.ExpiresAt == 0:
+		return -1, nil
+	default:
+		return time.Until(time.UnixMilli(item.ExpiresAt)), nil
+	}
+}
+
+// setAdd adds members to a set (must hold lock)
+func (m *Memory) setAdd(key string, members ...any) error {
+	item, err := m.collection(key, isSet, newSet)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		item.Set[memberString(member)] = true
+	}
+	m.dirty = true
+	return nil
+}
+
+// SetAdd adds items to a set
+func (m *Memory) SetAdd(ctx context.Context, key string, members ...any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.setAdd(key, members...)
+}
+
+// setRemove removes members from a set (must hold lock)
+func (m *Memory) setRemove(key string, members ...any) error {
+	item, err := m.collection(key, isSet, nil)
+	if err != nil || item == nil {
+		return err
+	}
+	for _, member := range members {
+		delete(item.Set, memberString(member))
+	}
+	m.removeIfEmpty(key, item)
+	m.dirty = true
+	return nil
+}
+
+// SetRemove removes items from a set
+func (m *Memory) SetRemove(ctx context.Context, key string, members ...any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.setRemove(key, members...)
+}
+
+// SetMembers returns all members of a set
+func (m *Memory) SetMembers(ctx context.Context, key string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, err := m.collection(key, isSet, nil)
+	if err != nil || item == nil {
+		return []string{}, err
+	}
+	members := make([]string, 0, len(item.Set))
+	for member := range item.Set {
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// SetIsMember checks if a value is in a set
+func (m *Memory) SetIsMember(ctx context.Context, key string, member any) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, err := m.collection(key, isSet, nil)
+	if err != nil || item == nil {
+		return false, err
+	}
+	return item.Set[memberString(member)], nil
+}
+
+// HashSet sets a field of a hash
+func (m *Memory) HashSet(ctx context.Context, key, field string, value any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, err := m.collection(key, isHash, newHash)
+	if err != nil {
+		return err
+	}
+	item.Hash[field] = memberString(value)
+	m.dirty = true
+	return nil
+}
+
+// HashGet returns a single field of a hash
+func (m *Memory) HashGet(ctx context.Context, key, field string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, err := m.collection(key, isHash, nil)
+	if err != nil {
+		return "", err
+	}
+	if item == nil {
+		return "", ErrNotFound
+	}
+	value, exists := item.Hash[field]
+	if !exists {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// HashGetAll returns all fields and values of a hash
+func (m *Memory) HashGetAll(ctx context.Context, key string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fields := make(map[string]string)
+	item, err := m.collection(key, isHash, nil)
+	if err != nil || item == nil {
+		return fields, err
+	}
+	for field, value := range item.Hash {
+		fields[field] = value
+	}
+	return fields, nil
+}
+
+// HashDelete removes fields from a hash
+func (m *Memory) HashDelete(ctx context.Context, key string, fields ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, err := m.collection(key, isHash, nil)
+	if err != nil || item == nil {
+		return err
+	}
+	for _, field := range fields {
+		delete(item.Hash, field)
+	}
+	m.removeIfEmpty(key, item)
+	m.dirty = true
+	return nil
+}
+
+// ListPush prepends a value to a list, so the newest value comes first
+func (m *Memory) ListPush(ctx context.Context, key string, value any) error {
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, err := m.collection(key, isList, newList)
+	if err != nil {
+		return err
+	}
+	item.List = slices.Insert(item.List, 0, data)
+	m.dirty = true
+	return nil
+}
+
+// listBounds converts Redis-style inclusive indexes into slice bounds for a list of length
+func listBounds(length int, start, stop int64) (int, int) {
+	if start < 0 {
+		start += int64(length)
+	}
+	if stop < 0 {
+		stop += int64(length)
+	}
+	start = max(start, 0)
+	stop = min(stop, int64(length)-1)
+	if start > stop {
+		return 0, 0
+	}
+	return int(start), int(stop) + 1
+}
+
+// ListRange returns the values of a list between two indexes, inclusive; negative indexes count from the end
+func (m *Memory) ListRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, err := m.collection(key, isList, nil)
+	if err != nil || item == nil {
+		return []string{}, err
+	}
+	from, to := listBounds(len(item.List), start, stop)
+	return slices.Clone(item.List[from:to]), nil
+}
+
+// ListTrim keeps only the values of a list between two indexes, inclusive
+func (m *Memory) ListTrim(ctx context.Context, key string, start, stop int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, err := m.collection(key, isList, nil)
+	if err != nil || item == nil {
+		return err
+	}
+	from, to := listBounds(len(item.List), start, stop)
+	item.List = slices.Clone(item.List[from:to])
+	m.removeIfEmpty(key, item)
+	m.dirty = true
+	return nil
+}
+
+// sortedSetAdd adds or rescores a sorted set member (must hold lock)
+func (m *Memory) sortedSetAdd(key string, score float64, member string) error {
+	item, err := m.collection(key, isSortedSet, newSortedSet)
+	if err != nil {
+		return err
+	}
+	item.SortedSet[member] = score
+	m.dirty = true
+	return nil
+}
+
+// SortedSetAdd adds a member to a sorted set, or updates its score
+func (m *Memory) SortedSetAdd(ctx context.Context, key string, score float64, member string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sortedSetAdd(key, score, member)
+}
+
+// sortedSetRemove removes sorted set members (must hold lock)
+func (m *Memory) sortedSetRemove(key string, members ...string) error {
+	item, err := m.collection(key, isSortedSet, nil)
+	if err != nil || item == nil {
+		return err
+	}
+	for _, member := range members {
+		delete(item.SortedSet, member)
+	}
+	m.removeIfEmpty(key, item)
+	m.dirty = true
+	return nil
+}
+
+// SortedSetRemove removes members from a sorted set
+func (m *Memory) SortedSetRemove(ctx context.Context, key string, members ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sortedSetRemove(key, members...)
+}
+
+// SortedSetRangeByScore returns the members of a sorted set with scores between min and max, lowest first
+// Bounds use Redis syntax, e.g. "-inf", "+inf" or "(100" for an exclusive bound
+func (m *Memory) SortedSetRangeByScore(ctx context.Context, key, min, max string) ([]string, error) {
+	scored, err := m.SortedSetRangeByScoreWithScores(ctx, key, min, max)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]string, len(scored))
+	for index, member := range scored {
+		members[index] = member.Member
+	}
+	return members, nil
+}
+
+// SortedSetRangeByScoreWithScores is SortedSetRangeByScore including each member's score
+func (m *Memory) SortedSetRangeByScoreWithScores(ctx context.Context, key, min, max string) ([]ScoredMember, error) {
+	above, err := parseScoreBound(min, false)
+	if err != nil {
+		return nil, err
+	}
+	below, err := parseScoreBound(max, true)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, err := m.collection(key, isSortedSet, nil)
+	if err != nil || item == nil {
+		return []ScoredMember{}, err
+	}
+
+	members := make([]ScoredMember, 0, len(item.SortedSet))
+	for member, score := range item.SortedSet {
+		if above(score) && below(score) {
+			members = append(members, ScoredMember{Member: member, Score: score})
+		}
+	}
+	// Order by score, then lexicographically like Redis
+	slices.SortFunc(members, func(a, b ScoredMember) int {
+		if a.Score != b.Score {
+			if a.Score < b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Member, b.Member)
+	})
+	return members, nil
+}
+
+// parseScoreBound turns a Redis score bound into a check against scores
+// upper selects whether the bound is a maximum or a minimum
+func parseScoreBound(bound string, upper bool) (func(float64) bool, error) {
+	exclusive := strings.HasPrefix(bound, "(")
+	bound = strings.TrimPrefix(bound, "(")
+
+	limit, err := strconv.ParseFloat(bound, 64) // accepts "-inf" and "+inf"
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case upper && exclusive:
+		return func(score float64) bool { return score < limit }, nil
+	case upper:
+		return func(score float64) bool { return score <= limit }, nil
+	case exclusive:
+		return func(score float64) bool { return score > limit }, nil
+	default:
+		return func(score float64) bool { return score >= limit }, nil
+	}
+}
+
+// SortedSetCount returns the number of members of a sorted set
+func (m *Memory) SortedSetCount(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, err := m.collection(key, isSortedSet, nil)
+	if err != nil || item == nil {
+		return 0, err
+	}
+	return int64(len(item.SortedSet)), nil
+}
+
+// Pipelined runs fn and applies every queued write at once, so other callers never see part of them
+func (m *Memory) Pipelined(ctx context.Context, fn func(pipe Pipeline) error) error {
+	queued := &memoryPipeline{}
+	if err := fn(queued); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var firstErr error
+	for _, apply := range queued.writes {
+		if err := apply(m); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close stops snapshotting and writes a final snapshot
+// It is safe to call more than once, like closing the Redis backend
+func (m *Memory) Close() error {
+	if m.snapshotPath == "" {
+		return nil
+	}
+	m.closeOnce.Do(func() { close(m.stop) })
+	m.wg.Wait()
+	return m.snapshot()
+}
+
+// snapshotLoop saves the store every interval until Close
+func (m *Memory) snapshotLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			if err := m.snapshot(); err != nil {
+				log.Printf("Error saving storage snapshot: %v", err)
+			}
+		}
+	}
+}
+
+// snapshot writes every live key to the snapshot file when something changed since the last one
+// It writes to a temporary file first, so a crash never leaves a truncated snapshot behind
+func (m *Memory) snapshot() error {
+	m.mu.Lock()
+	if !m.dirty {
+		m.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	for key, item := range m.items {
+		if item.expired(now) {
+			delete(m.items, key)
+		}
+	}
+	data, err := json.Marshal(m.items)
+	m.dirty = false
+	m.mu.Unlock()
+	if err != nil {
+		m.markDirty()
+		return err
+	}
+
+	if err := m.writeSnapshot(data); err != nil {
+		// Keep the changes pending so the next snapshot tries again
+		m.markDirty()
+		return err
+	}
+	return nil
+}
+
+// markDirty flags the store as changed since the last saved snapshot
+func (m *Memory) markDirty() {
+	m.mu.Lock()
+	m.dirty = true
+	m.mu.Unlock()
+}
+
+// writeSnapshot replaces the snapshot file with data through a temporary file and a rename
+func (m *Memory) writeSnapshot(data []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(m.snapshotPath), filepath.Base(m.snapshotPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), m.snapshotPath)
+}
+
+// load restores the store from the snapshot file, if there is one
+func (m *Memory) load() error {
+	data, err := os.ReadFile(m.snapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &m.items)
+}
+
+// memoryPipeline queues Pipeline writes until the store applies them under a single lock
+type memoryPipeline struct {
+	writes []func(m *Memory) error
+}
+
+func (p *memoryPipeline) queue(write func(m *Memory) error) {
+	p.writes = append(p.writes, write)
+}
+
+func (p *memoryPipeline) Set(key string, value any, ttl time.Duration) {
+	data, err := encodeValue(value)
+	p.queue(func(m *Memory) error {
+		if err != nil {
+			return err
+		}
+		m.set(key, data, ttl)
+		return nil
+	})
+}
+
+func (p *memoryPipeline) Delete(keys ...string) {
+	p.queue(func(m *Memory) error {
+		m.delete(keys...)
+		return nil
+	})
+}
+
+func (p *memoryPipeline) SetAdd(key string, members ...any) {
+	p.queue(func(m *Memory) error { return m.setAdd(key, members...) })
+}
+
+func (p *memoryPipeline) SetRemove(key string, members ...any) {
+	p.queue(func(m *Memory) error { return m.setRemove(key, members...) })
+}
+
+func (p *memoryPipeline) SortedSetAdd(key string, score float64, member string) {
+	p.queue(func(m *Memory) error { return m.sortedSetAdd(key, score, member) })
+}
+
+func (p *memoryPipeline) SortedSetRemove(key string, members ...string) {
+	p.queue(func(m *Memory) error { return m.sortedSetRemove(key, members...) })
+}
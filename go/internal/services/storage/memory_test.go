@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseScoreBound(t *testing.T) {
+	tests := []struct {
+		bound  string
+		upper  bool
+		score  float64
+		within bool
+	}{
+		{bound: "-inf", score: math.Inf(-1), within: true},
+		{bound: "-inf", score: -1e300, within: true},
+		{bound: "+inf", upper: true, score: math.Inf(1), within: true},
+		{bound: "+inf", upper: true, score: 1e300, within: true},
+		{bound: "+inf", score: 1e300, within: false},
+		{bound: "-inf", upper: true, score: -1e300, within: false},
+		{bound: "5", score: 5, within: true},
+		{bound: "5", upper: true, score: 5, within: true},
+		{bound: "(5", score: 5, within: false},
+		{bound: "(5", score: 5.5, within: true},
+		{bound: "(5", upper: true, score: 5, within: false},
+		{bound: "(5", upper: true, score: 4.5, within: true},
+		{bound: "(-inf", score: -1e300, within: true},
+		{bound: "(+inf", upper: true, score: 1e300, within: true},
+		{bound: "1700000000", upper: true, score: 1700000001, within: false},
+	}
+
+	for _, test := range tests {
+		check, err := parseScoreBound(test.bound, test.upper)
+		if err != nil {
+			t.Errorf("parseScoreBound(%q, %v): %v", test.bound, test.upper, err)
+			continue
+		}
+		if got := check(test.score); got != test.within {
+			t.Errorf("parseScoreBound(%q, upper %v)(%v) = %v, want %v", test.bound, test.upper, test.score, got, test.within)
+		}
+	}
+
+	for _, bound := range []string{"", "(", "soon", "((5"} {
+		if _, err := parseScoreBound(bound, false); err == nil {
+			t.Errorf("parseScoreBound(%q) succeeded, want an error", bound)
+		}
+	}
+}
+
+func TestMemoryDropsExpiredItems(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewMemory("", 0)
+	if err != nil {
+		t.Fatalf("NewMemory: %v", err)
+	}
+
+	if err := store.Set(ctx, "cache", "value", time.Hour); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	// Expire the key without waiting for it
+	store.items["cache"].ExpiresAt = time.Now().Add(-time.Millisecond).UnixMilli()
+
+	if exists, _ := store.Exists(ctx, "cache"); exists {
+		t.Error("expired key still exists")
+	}
+	if _, found := store.items["cache"]; found {
+		t.Error("expired key was not dropped on access")
+	}
+	if !store.dirty {
+		t.Error("dropping an expired key did not mark the store for a snapshot")
+	}
+}
+
+func TestMemorySnapshot(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "storage.json")
+
+	store, err := NewMemory(path, 0)
+	if err != nil {
+		t.Fatalf("NewMemory: %v", err)
+	}
+	if err := store.Set(ctx, "kept", "value", time.Hour); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := store.Set(ctx, "expired", "value", time.Hour); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	store.items["expired"].ExpiresAt = time.Now().Add(-time.Millisecond).UnixMilli()
+	if err := store.SortedSetAdd(ctx, "zset", 2, "member"); err != nil {
+		t.Fatalf("SortedSetAdd: %v", err)
+	}
+	if err := store.ListPush(ctx, "list", "entry"); err != nil {
+		t.Fatalf("ListPush: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// The snapshot is written to a temporary file and renamed into place
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "storage.json" {
+		t.Errorf("snapshot directory holds %v, want only storage.json", entries)
+	}
+
+	reloaded, err := NewMemory(path, 0)
+	if err != nil {
+		t.Fatalf("NewMemory reload: %v", err)
+	}
+	defer reloaded.Close()
+
+	var value string
+	if err := reloaded.Get(ctx, "kept", &value); err != nil || value != "value" {
+		t.Errorf("reloaded value = %q, %v, want value", value, err)
+	}
+	if ttl, _ := reloaded.TTL(ctx, "kept"); ttl <= 0 || ttl > time.Hour {
+		t.Errorf("reloaded TTL = %v, want the TTL kept", ttl)
+	}
+	if _, found := reloaded.items["expired"]; found {
+		t.Error("expired key was saved in the snapshot")
+	}
+	if members, _ := reloaded.SortedSetRangeByScoreWithScores(ctx, "zset", "-inf", "+inf"); len(members) != 1 || members[0] != (ScoredMember{Member: "member", Score: 2}) {
+		t.Errorf("reloaded sorted set = %v, want member at 2", members)
+	}
+	if list, _ := reloaded.ListRange(ctx, "list", 0, -1); len(list) != 1 || list[0] != "entry" {
+		t.Errorf("reloaded list = %v, want [entry]", list)
+	}
+}
+
+func TestMemorySnapshotSkipsUnchangedStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+	store, err := NewMemory(path, 0)
+	if err != nil {
+		t.Fatalf("NewMemory: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Stat = %v, want no snapshot for a store that never changed", err)
+	}
+}
+
+func TestMemoryRefusesCorruptSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := NewMemory(path, 0); err == nil {
+		t.Error("NewMemory with a corrupt snapshot succeeded, want an error")
+	}
+
+	// The snapshot is left alone so it can be recovered
+	if data, _ := os.ReadFile(path); string(data) != "{not json" {
+		t.Errorf("corrupt snapshot was overwritten with %q", data)
+	}
+}
+
+func TestMemoryCloseTwiceWithSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+	store, err := NewMemory(path, time.Hour)
+	if err != nil {
+		t.Fatalf("NewMemory: %v", err)
+	}
+	if err := store.Set(context.Background(), "key", "value", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	for attempt := range 2 {
+		if err := store.Close(); err != nil {
+			t.Errorf("Close #%d: %v", attempt+1, err)
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Stat = %v, want the snapshot written", err)
+	}
+}
+
+func TestMemoryFailedSnapshotStaysDirty(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	store, err := NewMemory(filepath.Join(dir, "storage.json"), 0)
+	if err != nil {
+		t.Fatalf("NewMemory: %v", err)
+	}
+	if err := store.Set(context.Background(), "key", "value", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	// The snapshot directory does not exist yet, so writing the temporary file fails
+	if err := store.snapshot(); err == nil {
+		t.Fatal("snapshot into a missing directory succeeded, want an error")
+	}
+	if !store.dirty {
+		t.Fatal("failed snapshot marked the changes as saved")
+	}
+
+	// The next snapshot retries the unsaved changes
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	reloaded, err := NewMemory(filepath.Join(dir, "storage.json"), 0)
+	if err != nil {
+		t.Fatalf("NewMemory reload: %v", err)
+	}
+	defer reloaded.Close()
+	if exists, _ := reloaded.Exists(context.Background(), "key"); !exists {
+		t.Error("changes from the failed snapshot were lost")
+	}
+}
@@ -0,0 +1,299 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is a Store backed by a Redis server
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis connects to the Redis server at redisURL
+func NewRedis(redisURL string) (*Redis, error) {
+	opt, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(opt)
+
+	// Test connection
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := client.Ping(ctx).Result(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	log.Println("Redis connection established")
+	return &Redis{client: client}, nil
+}
+
+// notFound maps go-redis' missing key error to ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Set stores a key-value pair with optional TTL
+func (r *Redis) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, key, data, ttl).Err()
+}
+
+// SetNX stores a key-value pair only when the key does not exist yet and reports whether it was stored
+func (r *Redis) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	data, err := encodeValue(value)
+	if err != nil {
+		return false, err
+	}
+	return r.client.SetNX(ctx, key, data, ttl).Result()
+}
+
+// Get retrieves a value by key and optionally unmarshals JSON
+func (r *Redis) Get(ctx context.Context, key string, dest any) error {
+	result, err := r.client.Get(ctx, key).Result()
+	if err != nil {
+		return notFound(err)
+	}
+	return decodeValue(result, dest)
+}
+
+// GetDelete retrieves a string value and removes its key in one step
+func (r *Redis) GetDelete(ctx context.Context, key string) (string, error) {
+	result, err := r.client.GetDel(ctx, key).Result()
+	return result, notFound(err)
+}
+
+// MGet returns the string values of several keys in one round trip
+// Missing keys are reported as false at their position
+func (r *Redis) MGet(ctx context.Context, keys ...string) ([]string, []bool, error) {
+	results, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := make([]string, len(results))
+	found := make([]bool, len(results))
+	for index, result := range results {
+		if value, ok := result.(string); ok {
+			values[index] = value
+			found[index] = true
+		}
+	}
+	return values, found, nil
+}
+
+// Delete removes keys
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// deleteIfValueScript deletes a key only while it still holds the expected value, atomically
+var deleteIfValueScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// DeleteIfValue removes a key only when it holds value and reports whether it was removed
+func (r *Redis) DeleteIfValue(ctx context.Context, key, value string) (bool, error) {
+	deleted, err := deleteIfValueScript.Run(ctx, r.client, []string{key}, value).Int()
+	return deleted == 1, err
+}
+
+// Exists checks if a key exists
+func (r *Redis) Exists(ctx context.Context, key string) (bool, error) {
+	result, err := r.client.Exists(ctx, key).Result()
+	return result == 1, err
+}
+
+// Keys returns all keys matching a pattern using incremental SCAN, which unlike KEYS never blocks Redis
+func (r *Redis) Keys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := r.client.Scan(ctx, 0, pattern, 500).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
+// Expire sets TTL on a key
+func (r *Redis) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return r.client.Expire(ctx, key, ttl).Err()
+}
+
+// TTL gets the time to live for a key
+func (r *Redis) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return r.client.TTL(ctx, key).Result()
+}
+
+// SetAdd adds items to a set
+func (r *Redis) SetAdd(ctx context.Context, key string, members ...any) error {
+	return r.client.SAdd(ctx, key, members...).Err()
+}
+
+// SetRemove removes items from a set
+func (r *Redis) SetRemove(ctx context.Context, key string, members ...any) error {
+	return r.client.SRem(ctx, key, members...).Err()
+}
+
+// SetMembers returns all members of a set
+func (r *Redis) SetMembers(ctx context.Context, key string) ([]string, error) {
+	return r.client.SMembers(ctx, key).Result()
+}
+
+// SetIsMember checks if a value is in a set
+func (r *Redis) SetIsMember(ctx context.Context, key string, member any) (bool, error) {
+	return r.client.SIsMember(ctx, key, member).Result()
+}
+
+// HashSet sets a field of a hash
+func (r *Redis) HashSet(ctx context.Context, key, field string, value any) error {
+	return r.client.HSet(ctx, key, field, value).Err()
+}
+
+// HashGet returns a single field of a hash
+func (r *Redis) HashGet(ctx context.Context, key, field string) (string, error) {
+	result, err := r.client.HGet(ctx, key, field).Result()
+	return result, notFound(err)
+}
+
+// HashGetAll returns all fields and values of a hash
+func (r *Redis) HashGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return r.client.HGetAll(ctx, key).Result()
+}
+
+// HashDelete removes fields from a hash
+func (r *Redis) HashDelete(ctx context.Context, key string, fields ...string) error {
+	return r.client.HDel(ctx, key, fields...).Err()
+}
+
+// ListPush prepends a value to a list, so the newest value comes first
+func (r *Redis) ListPush(ctx context.Context, key string, value any) error {
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+	return r.client.LPush(ctx, key, data).Err()
+}
+
+// ListRange returns the values of a list between two indexes, inclusive; negative indexes count from the end
+func (r *Redis) ListRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return r.client.LRange(ctx, key, start, stop).Result()
+}
+
+// ListTrim keeps only the values of a list between two indexes, inclusive
+func (r *Redis) ListTrim(ctx context.Context, key string, start, stop int64) error {
+	return r.client.LTrim(ctx, key, start, stop).Err()
+}
+
+// SortedSetAdd adds a member to a sorted set, or updates its score
+func (r *Redis) SortedSetAdd(ctx context.Context, key string, score float64, member string) error {
+	return r.client.ZAdd(ctx, key, redis.Z{Score: score, Member: member}).Err()
+}
+
+// SortedSetRemove removes members from a sorted set
+func (r *Redis) SortedSetRemove(ctx context.Context, key string, members ...string) error {
+	return r.client.ZRem(ctx, key, stringsToAny(members)...).Err()
+}
+
+// SortedSetRangeByScore returns the members of a sorted set with scores between min and max, lowest first
+// Bounds use Redis syntax, e.g. "-inf", "+inf" or "(100" for an exclusive bound
+func (r *Redis) SortedSetRangeByScore(ctx context.Context, key, min, max string) ([]string, error) {
+	return r.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: min, Max: max}).Result()
+}
+
+// SortedSetRangeByScoreWithScores is SortedSetRangeByScore including each member's score
+func (r *Redis) SortedSetRangeByScoreWithScores(ctx context.Context, key, min, max string) ([]ScoredMember, error) {
+	results, err := r.client.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{Min: min, Max: max}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]ScoredMember, len(results))
+	for index, result := range results {
+		members[index] = ScoredMember{Member: memberString(result.Member), Score: result.Score}
+	}
+	return members, nil
+}
+
+// SortedSetCount returns the number of members of a sorted set
+func (r *Redis) SortedSetCount(ctx context.Context, key string) (int64, error) {
+	return r.client.ZCard(ctx, key).Result()
+}
+
+// Pipelined runs fn against a pipeline and sends every queued command at once
+// It returns the first command error, if any
+func (r *Redis) Pipelined(ctx context.Context, fn func(pipe Pipeline) error) error {
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		queued := &redisPipeline{ctx: ctx, pipe: pipe}
+		if err := fn(queued); err != nil {
+			return err
+		}
+		// A value that could not be encoded aborts the whole pipeline
+		return queued.err
+	})
+	return err
+}
+
+// Close closes the Redis connection
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+// redisPipeline queues Pipeline writes on a go-redis pipeline
+type redisPipeline struct {
+	ctx  context.Context
+	pipe redis.Pipeliner
+	err  error
+}
+
+func (p *redisPipeline) Set(key string, value any, ttl time.Duration) {
+	data, err := encodeValue(value)
+	if err != nil {
+		p.err = err
+		return
+	}
+	p.pipe.Set(p.ctx, key, data, ttl)
+}
+
+func (p *redisPipeline) Delete(keys ...string) {
+	p.pipe.Del(p.ctx, keys...)
+}
+
+func (p *redisPipeline) SetAdd(key string, members ...any) {
+	p.pipe.SAdd(p.ctx, key, members...)
+}
+
+func (p *redisPipeline) SetRemove(key string, members ...any) {
+	p.pipe.SRem(p.ctx, key, members...)
+}
+
+func (p *redisPipeline) SortedSetAdd(key string, score float64, member string) {
+	p.pipe.ZAdd(p.ctx, key, redis.Z{Score: score, Member: member})
+}
+
+func (p *redisPipeline) SortedSetRemove(key string, members ...string) {
+	p.pipe.ZRem(p.ctx, key, stringsToAny(members)...)
+}
+
+// stringsToAny converts members to the variadic form go-redis expects
+func stringsToAny(values []string) []any {
+	converted := make([]any, len(values))
+	for index, value := range values {
+		converted[index] = value
+	}
+	return converted
+}
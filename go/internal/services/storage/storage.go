@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"discord-anime-bot/internal/config"
+)
+
+// Backends that can be selected with STORAGE_BACKEND
+const (
+	BackendRedis  = "redis"
+	BackendMemory = "memory"
+)
+
+// ErrNotFound is returned when a requested key or hash field does not exist
+var ErrNotFound = errors.New("storage: key not found")
+
+// IsNotFound reports whether err means the requested key does not exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// Store is the key-value storage shared by the bot's services
+// Values passed to Set that are not strings are stored as JSON, and Get decodes them back
+type Store interface {
+	// Keys and TTLs
+	Set(ctx context.Context, key string, value any, ttl time.Duration) error
+	SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error)
+	Get(ctx context.Context, key string, dest any) error
+	GetDelete(ctx context.Context, key string) (string, error)
+	MGet(ctx context.Context, keys ...string) ([]string, []bool, error)
+	Delete(ctx context.Context, keys ...string) error
+	DeleteIfValue(ctx context.Context, key, value string) (bool, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Keys(ctx context.Context, pattern string) ([]string, error)
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	TTL(ctx context.Context, key string) (time.Duration, error)
+
+	// Sets
+	SetAdd(ctx context.Context, key string, members ...any) error
+	SetRemove(ctx context.Context, key string, members ...any) error
+	SetMembers(ctx context.Context, key string) ([]string, error)
+	SetIsMember(ctx context.Context, key string, member any) (bool, error)
+
+	// Hashes
+	HashSet(ctx context.Context, key, field string, value any) error
+	HashGet(ctx context.Context, key, field string) (string, error)
+	HashGetAll(ctx context.Context, key string) (map[string]string, error)
+	HashDelete(ctx context.Context, key string, fields ...string) error
+
+	// Lists
+	ListPush(ctx context.Context, key string, value any) error
+	ListRange(ctx context.Context, key string, start, stop int64) ([]string, error)
+	ListTrim(ctx context.Context, key string, start, stop int64) error
+
+	// Sorted sets
+	SortedSetAdd(ctx context.Context, key string, score float64, member string) error
+	SortedSetRemove(ctx context.Context, key string, members ...string) error
+	SortedSetRangeByScore(ctx context.Context, key, min, max string) ([]string, error)
+	SortedSetRangeByScoreWithScores(ctx context.Context, key, min, max string) ([]ScoredMember, error)
+	SortedSetCount(ctx context.Context, key string) (int64, error)
+
+	// Pipelined runs fn and applies every queued write together
+	Pipelined(ctx context.Context, fn func(pipe Pipeline) error) error
+
+	Close() error
+}
+
+// ScoredMember is a sorted set member with its score
+type ScoredMember struct {
+	Member string
+	Score  float64
+}
+
+// Pipeline queues writes that a Store applies together, in a single round trip for Redis
+type Pipeline interface {
+	Set(key string, value any, ttl time.Duration)
+	Delete(keys ...string)
+	SetAdd(key string, members ...any)
+	SetRemove(key string, members ...any)
+	SortedSetAdd(key string, score float64, member string)
+	SortedSetRemove(key string, members ...string)
+}
+
+// Open creates the store selected in the configuration
+// When Redis cannot be reached the bot falls back to the in-memory store, so it keeps running
+func Open(cfg *config.Config) Store {
+	if cfg.StorageBackend == BackendRedis {
+		store, err := NewRedis(cfg.RedisURL)
+		if err == nil {
+			return store
+		}
+		log.Printf("Failed to connect to Redis: %v", err)
+		log.Println("Falling back to in-memory storage")
+	}
+
+	store, err := NewMemory(cfg.StorageSnapshotPath, cfg.StorageSnapshotInterval)
+	if err != nil {
+		// Leave the unreadable snapshot alone rather than overwrite it
+		log.Printf("Error loading storage snapshot %s, continuing without snapshots: %v", cfg.StorageSnapshotPath, err)
+		store, _ = NewMemory("", 0)
+	}
+	return store
+}
+
+// encodeValue converts a value to the string that is stored, using JSON for anything but strings
+func encodeValue(value any) (string, error) {
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeValue copies a stored string into dest, decoding JSON unless dest is a string
+func decodeValue(data string, dest any) error {
+	if text, ok := dest.(*string); ok {
+		*text = data
+		return nil
+	}
+	return json.Unmarshal([]byte(data), dest)
+}
+
+// memberString formats a set member or hash value the way Redis stores it
+func memberString(member any) string {
+	if text, ok := member.(string); ok {
+		return text
+	}
+	return fmt.Sprint(member)
+}
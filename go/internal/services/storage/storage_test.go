@@ -0,0 +1,460 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testStores returns the backends the Store contract is checked against
+// The memory store is always tested; Redis only when STORAGE_TEST_REDIS_URL points at a server
+// whose keys under the test's prefix may be overwritten
+func testStores(t *testing.T) map[string]func(t *testing.T) (Store, string) {
+	t.Helper()
+	stores := map[string]func(t *testing.T) (Store, string){
+		BackendMemory: func(t *testing.T) (Store, string) {
+			store, err := NewMemory("", 0)
+			if err != nil {
+				t.Fatalf("NewMemory: %v", err)
+			}
+			t.Cleanup(func() { _ = store.Close() })
+			return store, ""
+		},
+	}
+
+	redisURL := os.Getenv("STORAGE_TEST_REDIS_URL")
+	if redisURL == "" {
+		return stores
+	}
+	stores[BackendRedis] = func(t *testing.T) (Store, string) {
+		store, err := NewRedis(redisURL)
+		if err != nil {
+			t.Fatalf("NewRedis: %v", err)
+		}
+		prefix := "storage-test:" + t.Name() + ":"
+		cleanup := func() {
+			keys, _ := store.Keys(context.Background(), prefix+"*")
+			if len(keys) > 0 {
+				_ = store.Delete(context.Background(), keys...)
+			}
+		}
+		cleanup()
+		t.Cleanup(func() {
+			cleanup()
+			_ = store.Close()
+		})
+		return store, prefix
+	}
+	return stores
+}
+
+// forEachStore runs test against every backend under test, with keys namespaced by the returned prefix
+func forEachStore(t *testing.T, test func(t *testing.T, ctx context.Context, store Store, prefix string)) {
+	for name, open := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			store, prefix := open(t)
+			test(t, context.Background(), store, prefix)
+		})
+	}
+}
+
+func TestStoreValues(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, store Store, prefix string) {
+		var text string
+		if err := store.Get(ctx, prefix+"missing", &text); !IsNotFound(err) {
+			t.Errorf("Get missing key err = %v, want ErrNotFound", err)
+		}
+
+		type entry struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}
+		if err := store.Set(ctx, prefix+"json", entry{ID: 1, Name: "Mushishi"}, 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		var decoded entry
+		if err := store.Get(ctx, prefix+"json", &decoded); err != nil || decoded != (entry{ID: 1, Name: "Mushishi"}) {
+			t.Errorf("Get = %+v, %v, want the stored entry", decoded, err)
+		}
+
+		if err := store.Set(ctx, prefix+"text", "plain", 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		if err := store.Get(ctx, prefix+"text", &text); err != nil || text != "plain" {
+			t.Errorf("Get = %q, %v, want the string stored as is", text, err)
+		}
+
+		values, found, err := store.MGet(ctx, prefix+"text", prefix+"missing")
+		if err != nil || !slices.Equal(found, []bool{true, false}) || values[0] != "plain" {
+			t.Errorf("MGet = %q, %v, %v, want only the first key found", values, found, err)
+		}
+
+		if value, err := store.GetDelete(ctx, prefix+"text"); err != nil || value != "plain" {
+			t.Errorf("GetDelete = %q, %v, want the stored value", value, err)
+		}
+		if _, err := store.GetDelete(ctx, prefix+"text"); !IsNotFound(err) {
+			t.Errorf("second GetDelete err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestStoreSetNX(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, store Store, prefix string) {
+		key := prefix + "lock"
+		if stored, err := store.SetNX(ctx, key, "first", time.Minute); err != nil || !stored {
+			t.Fatalf("SetNX on a new key = %v, %v, want stored", stored, err)
+		}
+		if stored, err := store.SetNX(ctx, key, "second", time.Minute); err != nil || stored {
+			t.Errorf("SetNX on an existing key = %v, %v, want not stored", stored, err)
+		}
+
+		var value string
+		if err := store.Get(ctx, key, &value); err != nil || value != "first" {
+			t.Errorf("value = %q, %v, want the first value kept", value, err)
+		}
+		if ttl, err := store.TTL(ctx, key); err != nil || ttl <= 0 || ttl > time.Minute {
+			t.Errorf("TTL = %v, %v, want the SetNX TTL", ttl, err)
+		}
+	})
+}
+
+func TestStoreDeleteIfValue(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, store Store, prefix string) {
+		key := prefix + "lock"
+		if err := store.Set(ctx, key, "owner", 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+
+		if deleted, err := store.DeleteIfValue(ctx, key, "someone else"); err != nil || deleted {
+			t.Errorf("DeleteIfValue with another value = %v, %v, want kept", deleted, err)
+		}
+		if exists, _ := store.Exists(ctx, key); !exists {
+			t.Error("key was removed by DeleteIfValue with another value")
+		}
+
+		if deleted, err := store.DeleteIfValue(ctx, key, "owner"); err != nil || !deleted {
+			t.Errorf("DeleteIfValue with the stored value = %v, %v, want deleted", deleted, err)
+		}
+		if exists, _ := store.Exists(ctx, key); exists {
+			t.Error("key still exists after DeleteIfValue")
+		}
+
+		if deleted, err := store.DeleteIfValue(ctx, key, "owner"); err != nil || deleted {
+			t.Errorf("DeleteIfValue on a missing key = %v, %v, want false without error", deleted, err)
+		}
+	})
+}
+
+func TestStoreTTL(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, store Store, prefix string) {
+		if ttl, err := store.TTL(ctx, prefix+"missing"); err != nil || ttl != -2 {
+			t.Errorf("TTL of a missing key = %v, %v, want -2", ttl, err)
+		}
+
+		if err := store.Set(ctx, prefix+"forever", "value", 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		if ttl, err := store.TTL(ctx, prefix+"forever"); err != nil || ttl != -1 {
+			t.Errorf("TTL of a key without expiry = %v, %v, want -1", ttl, err)
+		}
+
+		if err := store.Expire(ctx, prefix+"forever", time.Hour); err != nil {
+			t.Fatalf("Expire: %v", err)
+		}
+		if ttl, err := store.TTL(ctx, prefix+"forever"); err != nil || ttl <= 59*time.Minute || ttl > time.Hour {
+			t.Errorf("TTL after Expire = %v, %v, want about an hour", ttl, err)
+		}
+
+		if err := store.Set(ctx, prefix+"short", "value", 100*time.Millisecond); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		if err := store.SetAdd(ctx, prefix+"short-set", "member"); err != nil {
+			t.Fatalf("SetAdd: %v", err)
+		}
+		if err := store.Expire(ctx, prefix+"short-set", 100*time.Millisecond); err != nil {
+			t.Fatalf("Expire: %v", err)
+		}
+		time.Sleep(250 * time.Millisecond)
+
+		var value string
+		if err := store.Get(ctx, prefix+"short", &value); !IsNotFound(err) {
+			t.Errorf("Get after expiry = %q, %v, want ErrNotFound", value, err)
+		}
+		for _, key := range []string{prefix + "short", prefix + "short-set"} {
+			if exists, _ := store.Exists(ctx, key); exists {
+				t.Errorf("%s still exists after its TTL", key)
+			}
+		}
+		if keys, _ := store.Keys(ctx, prefix+"short*"); len(keys) != 0 {
+			t.Errorf("Keys lists expired keys %v", keys)
+		}
+		if stored, err := store.SetNX(ctx, prefix+"short", "again", 0); err != nil || !stored {
+			t.Errorf("SetNX on an expired key = %v, %v, want stored", stored, err)
+		}
+	})
+}
+
+func TestStoreKeys(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, store Store, prefix string) {
+		for _, key := range []string{"notification:1", "notification:2", "cache:1"} {
+			if err := store.Set(ctx, prefix+key, "value", 0); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+		}
+
+		keys, err := store.Keys(ctx, prefix+"notification:*")
+		if err != nil {
+			t.Fatalf("Keys: %v", err)
+		}
+		slices.Sort(keys)
+		if want := []string{prefix + "notification:1", prefix + "notification:2"}; !slices.Equal(keys, want) {
+			t.Errorf("Keys = %v, want %v", keys, want)
+		}
+	})
+}
+
+func TestStoreCollections(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, store Store, prefix string) {
+		set := prefix + "set"
+		if err := store.SetAdd(ctx, set, "a", 2, "a"); err != nil {
+			t.Fatalf("SetAdd: %v", err)
+		}
+		members, err := store.SetMembers(ctx, set)
+		slices.Sort(members)
+		if err != nil || !slices.Equal(members, []string{"2", "a"}) {
+			t.Errorf("SetMembers = %v, %v, want [2 a]", members, err)
+		}
+		if isMember, _ := store.SetIsMember(ctx, set, 2); !isMember {
+			t.Error("SetIsMember(2) = false, want true")
+		}
+		if err := store.SetRemove(ctx, set, "a", 2); err != nil {
+			t.Fatalf("SetRemove: %v", err)
+		}
+		if exists, _ := store.Exists(ctx, set); exists {
+			t.Error("set still exists after removing its last member")
+		}
+		if members, err := store.SetMembers(ctx, set); err != nil || len(members) != 0 {
+			t.Errorf("SetMembers of a missing set = %v, %v, want empty", members, err)
+		}
+
+		hash := prefix + "hash"
+		if err := store.HashSet(ctx, hash, "count", 3); err != nil {
+			t.Fatalf("HashSet: %v", err)
+		}
+		if value, err := store.HashGet(ctx, hash, "count"); err != nil || value != "3" {
+			t.Errorf("HashGet = %q, %v, want 3", value, err)
+		}
+		if _, err := store.HashGet(ctx, hash, "missing"); !IsNotFound(err) {
+			t.Errorf("HashGet missing field err = %v, want ErrNotFound", err)
+		}
+		if fields, err := store.HashGetAll(ctx, hash); err != nil || len(fields) != 1 || fields["count"] != "3" {
+			t.Errorf("HashGetAll = %v, %v, want count=3", fields, err)
+		}
+		if err := store.HashDelete(ctx, hash, "count"); err != nil {
+			t.Fatalf("HashDelete: %v", err)
+		}
+		if exists, _ := store.Exists(ctx, hash); exists {
+			t.Error("hash still exists after deleting its last field")
+		}
+
+		// Collection operations on a string key fail like Redis' WRONGTYPE
+		if err := store.Set(ctx, prefix+"string", "value", 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		if err := store.SetAdd(ctx, prefix+"string", "member"); err == nil {
+			t.Error("SetAdd on a string key succeeded, want a wrong type error")
+		}
+		if err := store.SortedSetAdd(ctx, prefix+"string", 1, "member"); err == nil {
+			t.Error("SortedSetAdd on a string key succeeded, want a wrong type error")
+		}
+	})
+}
+
+func TestStoreLists(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, store Store, prefix string) {
+		list := prefix + "list"
+		for _, value := range []string{"1", "2", "3", "4", "5"} {
+			if err := store.ListPush(ctx, list, value); err != nil {
+				t.Fatalf("ListPush: %v", err)
+			}
+		}
+
+		tests := []struct {
+			start, stop int64
+			want        []string
+		}{
+			{0, -1, []string{"5", "4", "3", "2", "1"}},
+			{0, 1, []string{"5", "4"}},
+			{-2, -1, []string{"2", "1"}},
+			{3, 100, []string{"2", "1"}},
+			{-100, 0, []string{"5"}},
+			{4, 2, []string{}},
+			{10, 20, []string{}},
+		}
+		for _, test := range tests {
+			if got, err := store.ListRange(ctx, list, test.start, test.stop); err != nil || !slices.Equal(got, test.want) {
+				t.Errorf("ListRange(%d, %d) = %v, %v, want %v", test.start, test.stop, got, err, test.want)
+			}
+		}
+
+		if err := store.ListTrim(ctx, list, 0, 2); err != nil {
+			t.Fatalf("ListTrim: %v", err)
+		}
+		if got, _ := store.ListRange(ctx, list, 0, -1); !slices.Equal(got, []string{"5", "4", "3"}) {
+			t.Errorf("after ListTrim = %v, want [5 4 3]", got)
+		}
+		if err := store.ListTrim(ctx, list, 5, 10); err != nil {
+			t.Fatalf("ListTrim: %v", err)
+		}
+		if exists, _ := store.Exists(ctx, list); exists {
+			t.Error("list still exists after trimming every value")
+		}
+	})
+}
+
+func TestStoreSortedSetRanges(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, store Store, prefix string) {
+		key := prefix + "zset"
+		for member, score := range map[string]float64{"b": 2, "a": 2, "c": 3, "low": -5, "one": 1} {
+			if err := store.SortedSetAdd(ctx, key, score, member); err != nil {
+				t.Fatalf("SortedSetAdd: %v", err)
+			}
+		}
+
+		tests := []struct {
+			min, max string
+			want     []string
+		}{
+			{"-inf", "+inf", []string{"low", "one", "a", "b", "c"}},
+			{"-inf", "inf", []string{"low", "one", "a", "b", "c"}},
+			{"1", "3", []string{"one", "a", "b", "c"}},
+			{"(1", "3", []string{"a", "b", "c"}},
+			{"1", "(3", []string{"one", "a", "b"}},
+			{"(1", "(3", []string{"a", "b"}},
+			{"2", "2", []string{"a", "b"}},
+			{"(2", "(2", []string{}},
+			{"-inf", "(1", []string{"low"}},
+			{"(3", "+inf", []string{}},
+			{"1.5", "2.5", []string{"a", "b"}},
+			{"3", "1", []string{}},
+		}
+		for _, test := range tests {
+			if got, err := store.SortedSetRangeByScore(ctx, key, test.min, test.max); err != nil || !slices.Equal(got, test.want) {
+				t.Errorf("SortedSetRangeByScore(%s, %s) = %v, %v, want %v", test.min, test.max, got, err, test.want)
+			}
+		}
+
+		scored, err := store.SortedSetRangeByScoreWithScores(ctx, key, "(1", "2")
+		if err != nil || !slices.Equal(scored, []ScoredMember{{Member: "a", Score: 2}, {Member: "b", Score: 2}}) {
+			t.Errorf("SortedSetRangeByScoreWithScores = %v, %v, want a and b at 2", scored, err)
+		}
+
+		if _, err := store.SortedSetRangeByScore(ctx, key, "soon", "+inf"); err == nil {
+			t.Error("SortedSetRangeByScore with an invalid bound succeeded, want an error")
+		}
+
+		// Adding an existing member rescores it
+		if err := store.SortedSetAdd(ctx, key, 10, "low"); err != nil {
+			t.Fatalf("SortedSetAdd: %v", err)
+		}
+		if got, _ := store.SortedSetRangeByScore(ctx, key, "(3", "+inf"); !slices.Equal(got, []string{"low"}) {
+			t.Errorf("after rescoring = %v, want [low]", got)
+		}
+		if count, err := store.SortedSetCount(ctx, key); err != nil || count != 5 {
+			t.Errorf("SortedSetCount = %d, %v, want 5", count, err)
+		}
+
+		if err := store.SortedSetRemove(ctx, key, "a", "b", "c", "low", "one"); err != nil {
+			t.Fatalf("SortedSetRemove: %v", err)
+		}
+		if exists, _ := store.Exists(ctx, key); exists {
+			t.Error("sorted set still exists after removing every member")
+		}
+		if got, err := store.SortedSetRangeByScore(ctx, key, "-inf", "+inf"); err != nil || len(got) != 0 {
+			t.Errorf("range of a missing sorted set = %v, %v, want empty", got, err)
+		}
+	})
+}
+
+func TestStorePipelined(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, store Store, prefix string) {
+		if err := store.Set(ctx, prefix+"old", "value", 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+
+		err := store.Pipelined(ctx, func(pipe Pipeline) error {
+			pipe.Set(prefix+"new", map[string]int{"episode": 3}, 0)
+			pipe.Delete(prefix + "old")
+			pipe.SetAdd(prefix+"set", "a", "b")
+			pipe.SetRemove(prefix+"set", "b")
+			pipe.SortedSetAdd(prefix+"zset", 1, "a")
+			pipe.SortedSetAdd(prefix+"zset", 2, "b")
+			pipe.SortedSetRemove(prefix+"zset", "a")
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Pipelined: %v", err)
+		}
+
+		var value map[string]int
+		if err := store.Get(ctx, prefix+"new", &value); err != nil || value["episode"] != 3 {
+			t.Errorf("piped Set = %v, %v, want episode 3", value, err)
+		}
+		if exists, _ := store.Exists(ctx, prefix+"old"); exists {
+			t.Error("piped Delete left the key behind")
+		}
+		if members, _ := store.SetMembers(ctx, prefix+"set"); !slices.Equal(members, []string{"a"}) {
+			t.Errorf("piped set = %v, want [a]", members)
+		}
+		if members, _ := store.SortedSetRangeByScore(ctx, prefix+"zset", "-inf", "+inf"); !slices.Equal(members, []string{"b"}) {
+			t.Errorf("piped sorted set = %v, want [b]", members)
+		}
+
+		// An error from fn discards the queued writes
+		errAbort := errors.New("abort")
+		err = store.Pipelined(ctx, func(pipe Pipeline) error {
+			pipe.Delete(prefix + "new")
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Errorf("Pipelined err = %v, want the error from fn", err)
+		}
+		if exists, _ := store.Exists(ctx, prefix+"new"); !exists {
+			t.Error("writes queued before fn failed were applied")
+		}
+	})
+}
+
+func TestStoreCloseTwice(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, store Store, prefix string) {
+		if err := store.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		// Closing again must not panic; Redis reports the client as already closed
+		_ = store.Close()
+	})
+}
+
+func TestEncodeValue(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{"plain", "plain"},
+		{42, "42"},
+		{[]string{"a"}, `["a"]`},
+		{map[string]bool{"dm": true}, `{"dm":true}`},
+	}
+	for _, test := range tests {
+		if got, err := encodeValue(test.value); err != nil || got != test.want {
+			t.Errorf("encodeValue(%v) = %q, %v, want %q", test.value, got, err, test.want)
+		}
+	}
+	if _, err := encodeValue(make(chan int)); err == nil || !strings.Contains(err.Error(), "unsupported type") {
+		t.Errorf("encodeValue(chan) err = %v, want a JSON error", err)
+	}
+}
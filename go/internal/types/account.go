@@ -0,0 +1,69 @@
+package types
+
+// AniListAccount is a Discord user's linked AniList account
+// Token holds the OAuth access token encrypted with the configured token key
+type AniListAccount struct {
+	AniListUserID int    `json:"anilistUserId"`
+	Name          string `json:"name"`
+	SiteURL       string `json:"siteUrl"`
+	Token         string `json:"token"`
+	ExpiresAt     int64  `json:"expiresAt"`
+	LinkedAt      int64  `json:"linkedAt"`
+}
+
+// OAuthTokenResponse represents AniList's response to an authorization code exchange
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// AniListViewer represents the AniList user an access token belongs to
+type AniListViewer struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	SiteURL string `json:"siteUrl"`
+}
+
+// ViewerResponse represents the response from the AniList viewer query
+type ViewerResponse struct {
+	Data struct {
+		Viewer AniListViewer `json:"Viewer"`
+	} `json:"data"`
+}
+
+// MediaListEntry represents an entry on an AniList user's anime list
+// Score is out of 10; timestamps are Unix seconds
+type MediaListEntry struct {
+	MediaID   int     `json:"mediaId"`
+	Status    string  `json:"status"`
+	Progress  int     `json:"progress"`
+	Score     float64 `json:"score"`
+	CreatedAt int64   `json:"createdAt"`
+	UpdatedAt int64   `json:"updatedAt"`
+}
+
+// MediaListCollectionResponse represents the response from the AniList media list collection query
+type MediaListCollectionResponse struct {
+	Data struct {
+		MediaListCollection struct {
+			Lists []struct {
+				IsCustomList bool             `json:"isCustomList"`
+				Entries      []MediaListEntry `json:"entries"`
+			} `json:"lists"`
+		} `json:"MediaListCollection"`
+	} `json:"data"`
+}
+
+// GraphQLMediaListVariables represents variables for GraphQL media list collection query
+type GraphQLMediaListVariables struct {
+	UserID int `json:"userId"`
+}
+
+// GraphQLSaveMediaListEntryVariables represents variables for the GraphQL save media list entry mutation
+type GraphQLSaveMediaListEntryVariables struct {
+	MediaID  int    `json:"mediaId"`
+	Status   string `json:"status"`
+	Progress int    `json:"progress"`
+	ScoreRaw int    `json:"scoreRaw"`
+}
@@ -37,8 +37,10 @@ type NextAiringEpisode struct {
 type AnimeMedia struct {
 	ID         int        `json:"id"`
 	Title      AnimeTitle `json:"title"`
+	Synonyms   []string   `json:"synonyms"`
 	Format     string     `json:"format"`
 	Status     string     `json:"status"`
+	SeasonYear int        `json:"seasonYear"`
 	CoverImage CoverImage `json:"coverImage"`
 	SiteURL    string     `json:"siteUrl"`
 }
@@ -49,6 +51,7 @@ type AnimeDetails struct {
 	Title             AnimeTitle         `json:"title"`
 	Status            string             `json:"status"`
 	Format            string             `json:"format"`
+	SeasonYear        int                `json:"seasonYear"`
 	Episodes          *int               `json:"episodes"`
 	NextAiringEpisode *NextAiringEpisode `json:"nextAiringEpisode"`
 	CoverImage        CoverImage         `json:"coverImage"`
@@ -70,6 +73,23 @@ type SeasonAnime struct {
 	Status     string     `json:"status"`
 }
 
+// AiringSchedule represents a single episode airing at a specific time
+type AiringSchedule struct {
+	ID       int                 `json:"id"`
+	Episode  int                 `json:"episode"`
+	AiringAt int                 `json:"airingAt"`
+	Media    AiringScheduleMedia `json:"media"`
+}
+
+// AiringScheduleMedia represents the anime an airing schedule entry belongs to
+type AiringScheduleMedia struct {
+	ID      int        `json:"id"`
+	Title   AnimeTitle `json:"title"`
+	Format  string     `json:"format"`
+	SiteURL string     `json:"siteUrl"`
+	IsAdult bool       `json:"isAdult"`
+}
+
 // Generic response types
 type AniListPageResponse[T any] struct {
 	Data struct {
@@ -92,21 +112,38 @@ type SearchResponse = AniListPageResponse[AnimeMedia]
 // AnimeDetailsResponse represents the response from AniList anime details API
 type AnimeDetailsResponse = AniListSingleResponse[AnimeDetails]
 
+// AnimeDetailsPageResponse represents the response from AniList batched anime details API
+type AnimeDetailsPageResponse = AniListPageResponse[AnimeDetails]
+
 // ReleasingAnimeResponse represents the response from AniList releasing anime API
 type ReleasingAnimeResponse = AniListPageResponse[ReleasingAnime]
 
 // SeasonAnimeResponse represents the response from AniList seasonal anime API
 type SeasonAnimeResponse = AniListPageResponse[SeasonAnime]
 
+// AiringScheduleResponse represents the response from AniList airing schedule API
+type AiringScheduleResponse struct {
+	Data struct {
+		Page struct {
+			PageInfo        PageInfo         `json:"pageInfo"`
+			AiringSchedules []AiringSchedule `json:"airingSchedules"`
+		} `json:"Page"`
+	} `json:"data"`
+}
+
 // AnimeMatch represents a match found by AI with confidence and reasoning
+// Verified is false when the closest AniList title only loosely matches the AI's recommendation
 type AnimeMatch struct {
-	Anime      AnimeMedia `json:"anime"`
-	Reason     string     `json:"reason"`
-	Confidence float64    `json:"confidence"`
+	Anime            AnimeMedia `json:"anime"`
+	Reason           string     `json:"reason"`
+	Confidence       float64    `json:"confidence"`
+	RecommendedTitle string     `json:"recommendedTitle"`
+	MatchScore       float64    `json:"matchScore"`
+	Verified         bool       `json:"verified"`
 }
 
-// OpenAIRecommendation represents a single anime recommendation from OpenAI
-type OpenAIRecommendation struct {
+// AIRecommendation represents a single anime recommendation from an AI provider
+type AIRecommendation struct {
 	Title      string  `json:"title"`
 	Reason     string  `json:"reason"`
 	Confidence float64 `json:"confidence"`
@@ -135,6 +172,12 @@ type GraphQLNextVariables struct {
 	ID int `json:"id"`
 }
 
+// GraphQLIDsVariables represents variables for GraphQL batched anime details query
+type GraphQLIDsVariables struct {
+	IDs     []int `json:"ids"`
+	PerPage int   `json:"perPage"`
+}
+
 // GraphQLSeasonVariables represents variables for GraphQL season query
 type GraphQLSeasonVariables struct {
 	Season     string `json:"season"`
@@ -144,13 +187,30 @@ type GraphQLSeasonVariables struct {
 	PerPage    int    `json:"perPage"`
 }
 
+// GraphQLAiringScheduleVariables represents variables for GraphQL airing schedule query
+// The airing bounds are exclusive Unix timestamps
+type GraphQLAiringScheduleVariables struct {
+	Page            int `json:"page"`
+	PerPage         int `json:"perPage"`
+	AiringAtGreater int `json:"airingAtGreater"`
+	AiringAtLesser  int `json:"airingAtLesser"`
+}
+
 // NotificationEntry represents a notification entry with timer
+// Recurring entries are subscriptions that reschedule themselves for every new episode;
+// an Episode of 0 means the subscription is waiting for AniList to announce the next airing date
+// DM entries are delivered to the user's direct messages instead of ChannelID
+// Auto entries are managed by the watchlist auto-notify watcher rather than created by hand
 type NotificationEntry struct {
 	AnimeID   int    `json:"animeId"`
 	ChannelID string `json:"channelId"`
+	GuildID   string `json:"guildId,omitempty"`
 	UserID    string `json:"userId"`
 	Episode   int    `json:"episode"`
 	AiringAt  int64  `json:"airingAt"`
+	Recurring bool   `json:"recurring,omitempty"`
+	DM        bool   `json:"dm,omitempty"`
+	Auto      bool   `json:"auto,omitempty"`
 }
 
 // PersistedNotification represents a notification entry for storage (same as NotificationEntry)
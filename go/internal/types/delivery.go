@@ -0,0 +1,21 @@
+package types
+
+// Delivery is an episode alert waiting in the delivery queue, kept until it is sent or given up on
+// Sent counts the alert's messages that already went out, so a retry resumes where the last attempt stopped
+type Delivery struct {
+	ID            string              `json:"id"`
+	AnimeID       int                 `json:"animeId"`
+	Episode       int                 `json:"episode"`
+	AiringAt      int64               `json:"airingAt"`
+	Title         *AnimeTitle         `json:"title,omitempty"` // nil when AniList could not be reached, looked up again on each attempt
+	CoverImage    string              `json:"coverImage,omitempty"`
+	Entries       []NotificationEntry `json:"entries"`
+	ChannelID     string              `json:"channelId,omitempty"` // channel the alert was last sent to
+	Sent          int                 `json:"sent,omitempty"`
+	Attempts      int                 `json:"attempts,omitempty"`
+	NextAttemptAt int64               `json:"nextAttemptAt"`
+	LastError     string              `json:"lastError,omitempty"`
+	Permanent     bool                `json:"permanent,omitempty"` // the last error cannot be fixed by retrying
+	CreatedAt     int64               `json:"createdAt"`
+	FailedAt      int64               `json:"failedAt,omitempty"`
+}
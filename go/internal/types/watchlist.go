@@ -0,0 +1,33 @@
+package types
+
+// Watchlist statuses
+const (
+	WatchlistPlanning  = "planning"
+	WatchlistWatching  = "watching"
+	WatchlistCompleted = "completed"
+	WatchlistDropped   = "dropped"
+	WatchlistPaused    = "paused"
+)
+
+// WatchlistStatuses lists every watchlist status in display order
+var WatchlistStatuses = []string{WatchlistWatching, WatchlistPlanning, WatchlistPaused, WatchlistCompleted, WatchlistDropped}
+
+// WatchlistStatusNames holds the display name of each watchlist status
+var WatchlistStatusNames = map[string]string{
+	WatchlistPlanning:  "Planning",
+	WatchlistWatching:  "Watching",
+	WatchlistCompleted: "Completed",
+	WatchlistDropped:   "Dropped",
+	WatchlistPaused:    "Paused",
+}
+
+// WatchlistEntry represents a single anime on a user's watchlist
+// Score is out of 10, with 0 meaning unscored; timestamps are Unix seconds
+type WatchlistEntry struct {
+	AnimeID   int     `json:"animeId"`
+	Status    string  `json:"status"`
+	Progress  int     `json:"progress"`
+	Score     float64 `json:"score,omitempty"`
+	AddedAt   int64   `json:"addedAt"`
+	UpdatedAt int64   `json:"updatedAt"`
+}
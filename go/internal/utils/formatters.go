@@ -58,3 +58,9 @@ func FormatCompactDateTime(date time.Time) string {
 func FormatRelativeTimestamp(date time.Time) string {
 	return fmt.Sprintf("<t:%d:R>", date.Unix())
 }
+
+// FormatZonedDateTime formats date and time as plain text in a specific time zone
+// Format: "Mon, Dec 25 15:30 JST"
+func FormatZonedDateTime(date time.Time, location *time.Location) string {
+	return date.In(location).Format("Mon, Jan 2 15:04 MST")
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeTitle lowercases a title and reduces punctuation and whitespace to single spaces
+func NormalizeTitle(title string) string {
+	var builder strings.Builder
+	lastSpace := true
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			builder.WriteRune(r)
+			lastSpace = false
+		} else if !lastSpace {
+			builder.WriteRune(' ')
+			lastSpace = true
+		}
+	}
+	return strings.TrimSpace(builder.String())
+}
+
+// TitleSimilarity returns how similar two titles are, from 0 (unrelated) to 1 (identical after normalization)
+// It takes the better of a character-level edit distance ratio and a word-level overlap score,
+// so both typos and reordered or missing words are tolerated
+func TitleSimilarity(a, b string) float64 {
+	a, b = NormalizeTitle(a), NormalizeTitle(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	return max(levenshteinRatio(a, b), tokenDice(a, b))
+}
+
+// levenshteinRatio returns 1 minus the edit distance divided by the longer string's length
+func levenshteinRatio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return 1 - float64(previous[len(rb)])/float64(longest)
+}
+
+// tokenDice returns the Dice coefficient of the two titles' word sets
+func tokenDice(a, b string) float64 {
+	wordsA := strings.Fields(a)
+	wordsB := strings.Fields(b)
+
+	setB := make(map[string]bool, len(wordsB))
+	for _, word := range wordsB {
+		setB[word] = true
+	}
+
+	seen := make(map[string]bool, len(wordsA))
+	shared := 0
+	for _, word := range wordsA {
+		if setB[word] && !seen[word] {
+			shared++
+		}
+		seen[word] = true
+	}
+
+	return 2 * float64(shared) / float64(len(seen)+len(setB))
+}
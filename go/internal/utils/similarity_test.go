@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Attack on Titan", "attack on titan"},
+		{"  Steins;Gate  ", "steins gate"},
+		{"Re:Zero − Starting Life in Another World", "re zero starting life in another world"},
+		{"Kaguya-sama: Love Is War!?", "kaguya sama love is war"},
+		{"進撃の巨人", "進撃の巨人"},
+		{"Mob Psycho 100", "mob psycho 100"},
+		{"!!!", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := NormalizeTitle(test.title); got != test.want {
+			t.Errorf("NormalizeTitle(%q) = %q, want %q", test.title, got, test.want)
+		}
+	}
+}
+
+func TestLevenshteinRatio(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"naruto", "naruto", 1},
+		{"naruto", "", 0},
+		{"", "naruto", 0},
+		{"kitten", "sitting", 1 - 3.0/7},
+		{"kimetsu no yaiba", "kimetsu no yaba", 1 - 1.0/16},
+		{"abc", "xyz", 0},
+		{"進撃の巨人", "進撃の巨", 1 - 1.0/5},
+	}
+
+	for _, test := range tests {
+		if got := levenshteinRatio(test.a, test.b); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("levenshteinRatio(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestTokenDice(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"attack on titan", "attack on titan", 1},
+		{"titan on attack", "attack on titan", 1},
+		{"attack on titan", "attack titan", 0.8},
+		{"fullmetal alchemist brotherhood", "fullmetal alchemist", 0.8},
+		{"boku no hero academia", "my hero academia", 4.0 / 7},
+		{"one piece", "naruto", 0},
+		{"a a b", "a b", 1},
+	}
+
+	for _, test := range tests {
+		if got := tokenDice(test.a, test.b); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("tokenDice(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		min     float64
+		max     float64
+		exactly bool
+	}{
+		{name: "identical", a: "Cowboy Bebop", b: "Cowboy Bebop", min: 1, exactly: true},
+		{name: "case", a: "COWBOY BEBOP", b: "cowboy bebop", min: 1, exactly: true},
+		{name: "punctuation", a: "Steins;Gate", b: "Steins Gate", min: 1, exactly: true},
+		{name: "punctuation and case", a: "Kaguya-sama: Love Is War", b: "kaguya sama love is war", min: 1, exactly: true},
+		{name: "empty first", a: "", b: "Naruto", min: 0, exactly: true},
+		{name: "empty second", a: "Naruto", b: "", min: 0, exactly: true},
+		{name: "both empty", a: "", b: "", min: 0, exactly: true},
+		{name: "only punctuation", a: "!!!", b: "???", min: 0, exactly: true},
+		{name: "romaji typo", a: "Kimetsu no Yaiba", b: "Kimetsu no Yaba", min: 0.8, max: 1},
+		{name: "missing subtitle", a: "Fullmetal Alchemist", b: "Fullmetal Alchemist: Brotherhood", min: 0.8, max: 1},
+		{name: "romaji and english variant", a: "Boku no Hero Academia", b: "My Hero Academia", min: 0.5, max: 0.8},
+		{name: "romaji and english translation", a: "Shingeki no Kyojin", b: "Attack on Titan", min: 0, max: 0.5},
+		{name: "unrelated", a: "One Piece", b: "Neon Genesis Evangelion", min: 0, max: 0.5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := TitleSimilarity(test.a, test.b)
+			if test.exactly {
+				if got != test.min {
+					t.Errorf("TitleSimilarity(%q, %q) = %v, want %v", test.a, test.b, got, test.min)
+				}
+				return
+			}
+			if got < test.min || got >= test.max {
+				t.Errorf("TitleSimilarity(%q, %q) = %v, want within [%v, %v)", test.a, test.b, got, test.min, test.max)
+			}
+			if reversed := TitleSimilarity(test.b, test.a); math.Abs(reversed-got) > 1e-9 {
+				t.Errorf("TitleSimilarity is not symmetric: %v and %v", got, reversed)
+			}
+		})
+	}
+}
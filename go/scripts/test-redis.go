@@ -6,7 +6,7 @@ import (
 	"os"
 	"time"
 
-	"discord-anime-bot/internal/services/redis"
+	"discord-anime-bot/internal/services/storage"
 
 	"github.com/joho/godotenv"
 )
@@ -25,11 +25,12 @@ func main() {
 	log.Println("Testing Redis connection and operations...")
 
 	// Initialize Redis
-	if err := redis.InitRedis(redisURL); err != nil {
+	store, err := storage.NewRedis(redisURL)
+	if err != nil {
 		log.Fatalf("Failed to connect to Redis: %v", err)
 	}
 	defer func() {
-		if err := redis.Close(); err != nil {
+		if err := store.Close(); err != nil {
 			log.Printf("Error closing Redis connection: %v", err)
 		}
 	}()
@@ -38,11 +39,11 @@ func main() {
 
 	// Test 1: Basic key-value operations
 	log.Println("1. Testing basic key-value operations...")
-	if err := redis.Set(ctx, "test:key", "test-value", 0); err != nil {
+	if err := store.Set(ctx, "test:key", "test-value", 0); err != nil {
 		log.Printf("Error setting key: %v", err)
 	} else {
 		var value string
-		if err := redis.Get(ctx, "test:key", &value); err != nil {
+		if err := store.Get(ctx, "test:key", &value); err != nil {
 			log.Printf("Error getting key: %v", err)
 		} else {
 			log.Printf("Set and get: %s", value)
@@ -55,11 +56,11 @@ func main() {
 		"name": "Test Anime",
 		"id":   12345,
 	}
-	if err := redis.Set(ctx, "test:json", testObj, 0); err != nil {
+	if err := store.Set(ctx, "test:json", testObj, 0); err != nil {
 		log.Printf("Error setting JSON: %v", err)
 	} else {
 		var retrievedObj map[string]interface{}
-		if err := redis.Get(ctx, "test:json", &retrievedObj); err != nil {
+		if err := store.Get(ctx, "test:json", &retrievedObj); err != nil {
 			log.Printf("Error getting JSON: %v", err)
 		} else {
 			log.Printf("JSON set and get: %+v", retrievedObj)
@@ -68,27 +69,27 @@ func main() {
 
 	// Test 3: Set operations (for watchlists)
 	log.Println("3. Testing set operations (watchlist simulation)...")
-	if err := redis.SetAdd(ctx, "test:watchlist:user123", 123, 456, 789); err != nil {
+	if err := store.SetAdd(ctx, "test:watchlist:user123", 123, 456, 789); err != nil {
 		log.Printf("Error adding to set: %v", err)
 	} else {
-		members, err := redis.SetMembers(ctx, "test:watchlist:user123")
+		members, err := store.SetMembers(ctx, "test:watchlist:user123")
 		if err != nil {
 			log.Printf("Error getting set members: %v", err)
 		} else {
 			log.Printf("Watchlist items: %v", members)
 		}
 
-		isInWatchlist, err := redis.SetIsMember(ctx, "test:watchlist:user123", 456)
+		isInWatchlist, err := store.SetIsMember(ctx, "test:watchlist:user123", 456)
 		if err != nil {
 			log.Printf("Error checking set membership: %v", err)
 		} else {
 			log.Printf("Is 456 in watchlist: %t", isInWatchlist)
 		}
 
-		if err := redis.SetRemove(ctx, "test:watchlist:user123", 456); err != nil {
+		if err := store.SetRemove(ctx, "test:watchlist:user123", 456); err != nil {
 			log.Printf("Error removing from set: %v", err)
 		} else {
-			updatedMembers, err := redis.SetMembers(ctx, "test:watchlist:user123")
+			updatedMembers, err := store.SetMembers(ctx, "test:watchlist:user123")
 			if err != nil {
 				log.Printf("Error getting updated set members: %v", err)
 			} else {
@@ -99,10 +100,10 @@ func main() {
 
 	// Test 4: TTL operations
 	log.Println("4. Testing TTL operations...")
-	if err := redis.Set(ctx, "test:ttl", "expires-soon", 5*time.Second); err != nil {
+	if err := store.Set(ctx, "test:ttl", "expires-soon", 5*time.Second); err != nil {
 		log.Printf("Error setting key with TTL: %v", err)
 	} else {
-		ttl, err := redis.TTL(ctx, "test:ttl")
+		ttl, err := store.TTL(ctx, "test:ttl")
 		if err != nil {
 			log.Printf("Error getting TTL: %v", err)
 		} else {
@@ -112,7 +113,7 @@ func main() {
 
 	// Test 5: Keys pattern matching
 	log.Println("5. Testing keys pattern matching...")
-	keys, err := redis.Keys(ctx, "test:*")
+	keys, err := store.Keys(ctx, "test:*")
 	if err != nil {
 		log.Printf("Error getting keys: %v", err)
 	} else {
@@ -123,7 +124,7 @@ func main() {
 	log.Println("6. Cleaning up test data...")
 	cleanupKeys := []string{"test:key", "test:json", "test:watchlist:user123", "test:ttl"}
 	for _, key := range cleanupKeys {
-		if err := redis.Delete(ctx, key); err != nil {
+		if err := store.Delete(ctx, key); err != nil {
 			log.Printf("Error deleting key %s: %v", key, err)
 		}
 	}